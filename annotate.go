@@ -0,0 +1,36 @@
+package hue
+
+// Span is a run of text, p[Start:End], that a RegexpWriter colors
+// with Hue
+type Span struct {
+	Start, End int
+	Hue        *Hue
+}
+
+// Annotate returns the spans of p that Write would color, in order,
+// without writing anything or counting the matches in the statistics.
+// Overlaps, priorities, whole-line rules and SetInvert are applied as
+// in Write. Replacement rules report their matches in p, which is not
+// rewritten, and lines dropped by SetGrep are annotated like the rest.
+//
+// Annotate is useful for testing a set of rules, and for rendering the
+// matches in formats other than escape sequences.
+func (w *RegexpWriter) Annotate(p []byte) []Span {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paint(p, true)
+	w.swap()
+	var spans []Span
+	for i := 0; i < len(p); {
+		sl := w.huemap[i]
+		j := i + 1
+		for j < len(p) && w.huemap[j] == sl {
+			j++
+		}
+		if sl != 0 && w.slots[sl-1].h != nil {
+			spans = append(spans, Span{i, j, w.slots[sl-1].h})
+		}
+		i = j
+	}
+	return spans
+}
@@ -0,0 +1,39 @@
+package hue
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRegexpWriterAnnotate(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red, green := New(Red, 0), New(Green, 0)
+	w.AddRuleString(red, "ERROR")
+	w.AddRulePriority(green, regexp.MustCompile(`\d+`), 1)
+	id := w.AddRuleString(red, "x")
+	w.SetWholeLine(id, true)
+
+	have := w.Annotate([]byte("ERROR at 42\nok\nx y\n"))
+	want := []Span{{0, 5, red}, {9, 11, green}, {15, 18, red}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if b.Len() != 0 {
+		t.Errorf("wrote %q", b.String())
+	}
+	for _, s := range w.Stats() {
+		if s.Matches != 0 {
+			t.Errorf("rule %d: counted %d matches", s.ID, s.Matches)
+		}
+	}
+
+	w.SetInvert(green)
+	have = w.Annotate([]byte("a ERROR b"))
+	want = []Span{{0, 2, green}, {7, 9, green}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("invert: have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,138 @@
+package hue
+
+import "bytes"
+
+// lexState is the part of an escape sequence a lexer is in
+type lexState uint8
+
+const (
+	lexText   lexState = iota
+	lexEsc             // after ESC
+	lexCSI             // after ESC [
+	lexInter           // after ESC and an intermediate byte
+	lexString          // in an OSC, DCS, SOS, PM or APC string
+	lexST              // after ESC in a string, expecting a backslash
+)
+
+// lexer splits a byte stream into text and ECMA-48 escape sequences.
+// A sequence may be split across calls to feed; the lexer keeps the
+// part read so far until the sequence ends.
+type lexer struct {
+	state lexState
+	seq   []byte
+}
+
+// feed splits p into text and complete escape sequences, in order,
+// and calls fn with each. Text is passed as a subslice of p. The
+// slice passed for a sequence is reused after fn returns.
+func (l *lexer) feed(p []byte, fn func(b []byte, esc bool)) {
+	for i := 0; i < len(p); {
+		if l.state == lexText {
+			j := bytes.IndexByte(p[i:], '\033')
+			if j < 0 {
+				fn(p[i:], false)
+				return
+			}
+			if j > 0 {
+				fn(p[i:i+j], false)
+			}
+			l.state = lexEsc
+			l.seq = append(l.seq[:0], '\033')
+			i += j + 1
+			continue
+		}
+		c := p[i]
+		i++
+		l.seq = append(l.seq, c)
+		done := false
+		switch l.state {
+		case lexEsc:
+			switch {
+			case c == '[':
+				l.state = lexCSI
+			case c == ']' || c == 'P' || c == 'X' || c == '^' || c == '_':
+				l.state = lexString
+			case c >= 0x20 && c <= 0x2f:
+				l.state = lexInter
+			default:
+				done = true
+			}
+		case lexCSI:
+			done = c >= 0x40 && c <= 0x7e
+		case lexInter:
+			done = c >= 0x30 && c <= 0x7e
+		case lexString:
+			switch c {
+			case '\a':
+				done = true
+			case '\033':
+				l.state = lexST
+			}
+		case lexST:
+			if c == '\\' {
+				done = true
+			} else {
+				l.state = lexString
+			}
+		}
+		if done {
+			l.state = lexText
+			fn(l.seq, true)
+		}
+	}
+}
+
+// seqEnd returns i, if p[i:] starts outside an escape sequence, or the
+// end of the sequence p[i-1] is in. It returns false if the sequence
+// doesn't end in p.
+func seqEnd(p []byte, i int) (int, bool) {
+	var l lexer
+	l.feed(p[:i], func([]byte, bool) {})
+	for ; l.state != lexText; i++ {
+		if i == len(p) {
+			return i, false
+		}
+		l.feed(p[i:i+1], func([]byte, bool) {})
+	}
+	return i, true
+}
+
+// output is what a filtering writer writes for one Write, and where in
+// the input its parts came from, to count the input bytes written if
+// the underlying writer fails part way
+type output struct {
+	b    []byte
+	runs []run
+}
+
+// run records that b[out:out+n] was copied from p[in:in+n]
+type run struct{ in, out, n int }
+
+// reset empties the output
+func (o *output) reset() {
+	o.b, o.runs = o.b[:0], o.runs[:0]
+}
+
+// copy appends b, which is a subslice of p
+func (o *output) copy(p, b []byte) {
+	o.runs = append(o.runs, run{cap(p) - cap(b), len(o.b), len(b)})
+	o.b = append(o.b, b...)
+}
+
+// add appends s, which doesn't come from the input
+func (o *output) add(s string) {
+	o.b = append(o.b, s...)
+}
+
+// count returns the number of input bytes in the first nb bytes of
+// the output
+func (o *output) count(nb int) int {
+	n := 0
+	for _, r := range o.runs {
+		if r.out >= nb {
+			break
+		}
+		n = r.in + min(r.n, nb-r.out)
+	}
+	return n
+}
@@ -0,0 +1,32 @@
+package hue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexer(t *testing.T) {
+	in := "a\033[1;31mb\033]8;;http://x\033\\c\033]0;title\a\033(Bd\033Me"
+	want := []string{"a", "<\033[1;31m>", "b", "<\033]8;;http://x\033\\>", "c",
+		"<\033]0;title\a>", "<\033(B>", "d", "<\033M>", "e"}
+
+	// Feed the input whole and one byte at a time
+	for _, size := range []int{len(in), 1} {
+		var l lexer
+		var have []string
+		for i := 0; i < len(in); i += size {
+			l.feed([]byte(in[i:min(i+size, len(in))]), func(b []byte, esc bool) {
+				if esc {
+					have = append(have, "<"+string(b)+">")
+				} else if n := len(have); n > 0 && !strings.HasPrefix(have[n-1], "<") {
+					have[n-1] += string(b)
+				} else {
+					have = append(have, string(b))
+				}
+			})
+		}
+		if strings.Join(have, "|") != strings.Join(want, "|") {
+			t.Errorf("size %d: have %q, want %q", size, have, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package hue
+
+import "math"
+
+// Blend returns a hue whose colors are interpolated between those of a
+// and b. A t of 0 selects a's colors and a t of 1 selects b's. Colors
+// are mixed in the OKLab color space so intermediate colors change
+// evenly in perceived lightness. A color that has no RGB value, such
+// as Default, is not mixed: the nearer hue's color is used instead.
+// The attributes and the underline style and color are those of the
+// nearer hue, a if t is below 0.5 and b otherwise.
+func Blend(a, b *Hue, t float64) *Hue {
+	t = clamp(t)
+	h := *b
+	if t < 0.5 {
+		h = *a
+	}
+	h.Fg, h.Bg = blend(a.Fg, b.Fg, t), blend(a.Bg, b.Bg, t)
+	return &h
+}
+
+// Lighten returns a copy of the hue with the lightness of its colors
+// increased by amount, a fraction between 0 and 1. Colors are converted
+// to truecolor; colors without an RGB value, such as Default, are kept.
+func (h *Hue) Lighten(amount float64) *Hue {
+	return h.adjust(func(hue, s, l float64) (float64, float64, float64) {
+		return hue, s, l + amount
+	})
+}
+
+// Darken returns a copy of the hue with the lightness of its colors
+// decreased by amount. See Lighten.
+func (h *Hue) Darken(amount float64) *Hue {
+	return h.Lighten(-amount)
+}
+
+// Saturate returns a copy of the hue with the saturation of its colors
+// increased by amount, a fraction between 0 and 1. A negative amount
+// desaturates. See Lighten.
+func (h *Hue) Saturate(amount float64) *Hue {
+	return h.adjust(func(hue, s, l float64) (float64, float64, float64) {
+		return hue, s + amount, l
+	})
+}
+
+// adjust returns a copy of the hue with fn applied to the HSL values
+// of its colors
+func (h *Hue) adjust(fn func(h, s, l float64) (float64, float64, float64)) *Hue {
+	n := *h
+	for _, c := range []*int{&n.Fg, &n.Bg} {
+		if r, g, b, ok := toRGB(*c); ok {
+			*c = HSL(fn(hsl(r, g, b)))
+		}
+	}
+	return &n
+}
+
+// blend interpolates between the colors c1 and c2
+func blend(c1, c2 int, t float64) int {
+	r1, g1, b1, ok1 := toRGB(c1)
+	r2, g2, b2, ok2 := toRGB(c2)
+	if !ok1 || !ok2 {
+		if t < 0.5 {
+			return c1
+		}
+		return c2
+	}
+	l1, a1, bb1 := oklab(r1, g1, b1)
+	l2, a2, bb2 := oklab(r2, g2, b2)
+	lerp := func(x, y float64) float64 { return x + (y-x)*t }
+	return RGB(fromOklab(lerp(l1, l2), lerp(a1, a2), lerp(bb1, bb2)))
+}
+
+// toRGB returns the components of the color c. It returns false if
+// c has no RGB value.
+func toRGB(c int) (r, g, b uint8, ok bool) {
+	switch {
+	case c&tagMask == tagRGB:
+		r, g, b = rgb(c)
+		return r, g, b, true
+	case c&tagMask == tag256:
+		r, g, b = paletteRGB(uint8(c))
+		return r, g, b, true
+	case c >= Black && c <= White:
+		r, g, b = paletteRGB(uint8(c - Black))
+		return r, g, b, true
+	case c >= BrightBlack && c <= BrightWhite:
+		r, g, b = paletteRGB(uint8(c - BrightBlack + 8))
+		return r, g, b, true
+	}
+	return 0, 0, 0, false
+}
+
+// linear converts an sRGB component to linear light
+func linear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// gamma converts a linear light component to sRGB
+func gamma(f float64) uint8 {
+	if f <= 0.0031308 {
+		f *= 12.92
+	} else {
+		f = 1.055*math.Pow(f, 1/2.4) - 0.055
+	}
+	return unit(f)
+}
+
+// oklab converts an sRGB color to OKLab
+func oklab(r, g, b uint8) (L, A, B float64) {
+	lr, lg, lb := linear(r), linear(g), linear(b)
+	l := math.Cbrt(0.4122214708*lr + 0.5363325363*lg + 0.0514459929*lb)
+	m := math.Cbrt(0.2119034982*lr + 0.6806995451*lg + 0.1073969566*lb)
+	s := math.Cbrt(0.0883024619*lr + 0.2817188376*lg + 0.6299787005*lb)
+	return 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+}
+
+// fromOklab converts an OKLab color to sRGB
+func fromOklab(L, A, B float64) (r, g, b uint8) {
+	l := L + 0.3963377774*A + 0.2158037573*B
+	m := L - 0.1055613458*A - 0.0638541728*B
+	s := L - 0.0894841775*A - 1.2914855480*B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return gamma(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		gamma(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		gamma(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s)
+}
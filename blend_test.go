@@ -0,0 +1,74 @@
+package hue
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBlend(t *testing.T) {
+	a := New(RGB(255, 0, 0), Default)
+	b := New(RGB(0, 0, 255), Blue)
+
+	if h := Blend(a, b, 0); h.Fg != a.Fg || h.Bg != a.Bg {
+		t.Errorf("Blend(0): have %+v", *h)
+	}
+	if h := Blend(a, b, 1); h.Fg != b.Fg || h.Bg != b.Bg {
+		t.Errorf("Blend(1): have %+v", *h)
+	}
+
+	h := Blend(a, b, 0.5)
+	r, g, bl := rgb(h.Fg)
+	if r < 100 || bl < 100 || g > 100 {
+		t.Errorf("Blend(0.5): have %s, expected a purple", hex(h.Fg))
+	}
+	if h.Bg != Blue {
+		t.Errorf("Blend(0.5): have background %x, want Blue", h.Bg)
+	}
+
+	a.Attr, a.UnderlineStyle = Bold, UnderlineCurly
+	b.Attr, b.UnderlineColor = Italic, Red
+	if h := Blend(a, b, 0.25); h.Attr != Bold || h.UnderlineStyle != UnderlineCurly || h.UnderlineColor != 0 {
+		t.Errorf("Blend(0.25): have %+v, want a's attributes", *h)
+	}
+	if h := Blend(a, b, 0.5); h.Attr != Italic || h.UnderlineStyle != 0 || h.UnderlineColor != Red {
+		t.Errorf("Blend(0.5): have %+v, want b's attributes", *h)
+	}
+
+	if h := Blend(New(Black, Black), New(RGB(255, 255, 255), White), 0.5); h.Fg&tagMask != tagRGB {
+		t.Errorf("Blend: basic colors were not mixed")
+	}
+}
+
+func TestOklabRoundTrip(t *testing.T) {
+	for _, c := range [][3]uint8{{0, 0, 0}, {255, 255, 255}, {255, 136, 0}, {30, 144, 255}} {
+		r, g, b := fromOklab(oklab(c[0], c[1], c[2]))
+		if r != c[0] || g != c[1] || b != c[2] {
+			t.Errorf("%v: have %d %d %d", c, r, g, b)
+		}
+	}
+}
+
+func TestLightenDarkenSaturate(t *testing.T) {
+	h := New(HSL(200, 0.5, 0.5), Default)
+	for _, tc := range []struct {
+		h       *Hue
+		s, l    float64
+		comment string
+	}{
+		{h.Lighten(0.2), 0.5, 0.7, "Lighten"},
+		{h.Darken(0.3), 0.5, 0.2, "Darken"},
+		{h.Saturate(0.25), 0.75, 0.5, "Saturate"},
+		{h.Lighten(1), 0, 1, "Lighten past white"},
+	} {
+		if tc.h.Bg != Default {
+			t.Errorf("%s: background changed to %x", tc.comment, tc.h.Bg)
+		}
+		_, s, l := hsl(rgb(tc.h.Fg))
+		if math.Abs(s-tc.s) > 0.02 || math.Abs(l-tc.l) > 0.02 {
+			t.Errorf("%s: have s=%.2f l=%.2f, want s=%.2f l=%.2f", tc.comment, s, l, tc.s, tc.l)
+		}
+	}
+	if h.Fg != HSL(200, 0.5, 0.5) {
+		t.Errorf("receiver modified")
+	}
+}
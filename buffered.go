@@ -0,0 +1,49 @@
+package hue
+
+import (
+	"bufio"
+	"io"
+)
+
+// BufferedWriter is a Writer that collects its colorized output in a
+// buffer and writes it to the underlying writer in large chunks. It
+// suits high-volume output, such as colorizing logs, where a write per
+// line would dominate the cost. Call Flush or Close when done.
+type BufferedWriter struct {
+	*Writer
+	buf *bufio.Writer
+	dst io.Writer
+}
+
+// NewBufferedWriter returns a BufferedWriter with the hue h and a 64KB
+// buffer
+func NewBufferedWriter(w io.Writer, h *Hue) *BufferedWriter {
+	buf := bufio.NewWriterSize(w, 64*1024)
+	n := &BufferedWriter{Writer: NewWriter(buf, h), buf: buf, dst: w}
+	n.color = detect(w)
+	return n
+}
+
+// Flush writes the buffered output to the underlying writer, and
+// flushes that too if it has a Flush method
+func (w *BufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return flushWrapped(w.dst)
+}
+
+// Close flushes the BufferedWriter. It does not close the underlying
+// writer.
+func (w *BufferedWriter) Close() error {
+	return w.Flush()
+}
+
+// Buffered returns the number of bytes waiting in the buffer
+func (w *BufferedWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Buffered()
+}
@@ -0,0 +1,23 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	var b bytes.Buffer
+	h := New(Red, 0)
+	w := NewBufferedWriter(&b, h)
+	w.WriteString("a")
+	w.WriteString("b")
+	if b.Len() != 0 || w.Buffered() == 0 {
+		t.Fatalf("wrote %q before Flush", b.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := b.String(), string(Encode(h, "a")+Encode(h, "b")); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,93 @@
+package hue
+
+// Builder constructs a hue one property at a time. Each method returns
+// the builder so calls can be chained:
+//
+//	h := hue.NewBuilder().Fg(hue.Red).Bg(hue.Default).Bold().Underline().Hue()
+type Builder struct {
+	h Hue
+}
+
+// NewBuilder returns a Builder for a hue with no colors set
+func NewBuilder() *Builder {
+	return new(Builder)
+}
+
+// Fg sets the foreground color
+func (b *Builder) Fg(c int) *Builder {
+	b.h.SetFg(c)
+	return b
+}
+
+// Bg sets the background color
+func (b *Builder) Bg(c int) *Builder {
+	b.h.SetBg(c)
+	return b
+}
+
+// Bold adds the Bold attribute
+func (b *Builder) Bold() *Builder {
+	b.h.Attr |= Bold
+	return b
+}
+
+// Italic adds the Italic attribute
+func (b *Builder) Italic() *Builder {
+	b.h.Attr |= Italic
+	return b
+}
+
+// Underline adds the Underline attribute
+func (b *Builder) Underline() *Builder {
+	b.h.Attr |= Underline
+	return b
+}
+
+// Strike adds the Strike attribute
+func (b *Builder) Strike() *Builder {
+	b.h.Attr |= Strike
+	return b
+}
+
+// Dim adds the Dim attribute
+func (b *Builder) Dim() *Builder {
+	b.h.Attr |= Dim
+	return b
+}
+
+// Blink adds the Blink attribute
+func (b *Builder) Blink() *Builder {
+	b.h.Attr |= Blink
+	return b
+}
+
+// Reverse adds the Reverse attribute
+func (b *Builder) Reverse() *Builder {
+	b.h.Attr |= Reverse
+	return b
+}
+
+// Conceal adds the Conceal attribute
+func (b *Builder) Conceal() *Builder {
+	b.h.Attr |= Conceal
+	return b
+}
+
+// UnderlineStyle sets the extended underline style
+func (b *Builder) UnderlineStyle(s UnderlineStyle) *Builder {
+	b.h.UnderlineStyle = s
+	return b
+}
+
+// UnderlineColor sets the underline color
+func (b *Builder) UnderlineColor(c int) *Builder {
+	b.h.UnderlineColor = normalize(c)
+	return b
+}
+
+// Hue returns a new hue with the properties set so far. The builder
+// may be modified further without affecting hues already returned.
+func (b *Builder) Hue() *Hue {
+	h := b.h
+	return &h
+}
@@ -0,0 +1,196 @@
+package hue
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Extended colors are tagged so their codes can't collide with the
+// ECMA-48 color codes. The low bits hold the palette index.
+const (
+	tag256  = 1 << 24
+	tagRGB  = 2 << 24
+	tagMask = 0xff << 24
+)
+
+// Color256 returns the color code for entry n of the 256-color palette.
+// Entries 0-15 are the basic and bright colors, 16-231 a 6x6x6 color
+// cube and 232-255 a grayscale ramp. The code may be used as a
+// foreground or background color.
+func Color256(n uint8) int {
+	return tag256 | int(n)
+}
+
+// RGB returns the color code for a 24-bit truecolor value. The code
+// may be used as a foreground or background color.
+func RGB(r, g, b uint8) int {
+	return tagRGB | int(r)<<16 | int(g)<<8 | int(b)
+}
+
+// Hex returns the truecolor code for a hex color string in the form
+// "#rrggbb" or "#rgb". The leading '#' is optional.
+func Hex(s string) (int, error) {
+	x := strings.TrimPrefix(s, "#")
+	if len(x) == 3 {
+		x = string([]byte{x[0], x[0], x[1], x[1], x[2], x[2]})
+	}
+	if len(x) != 6 {
+		return 0, fmt.Errorf("hue: bad hex color %q", s)
+	}
+	v, err := strconv.ParseUint(x, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("hue: bad hex color %q", s)
+	}
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+// HSL returns the truecolor code for a hue h in degrees, and a
+// saturation s and lightness l between 0 and 1.
+func HSL(h, s, l float64) int {
+	s, l = clamp(s), clamp(l)
+	c := (1 - math.Abs(2*l-1)) * s
+	return hcm(h, c, l-c/2)
+}
+
+// HSV returns the truecolor code for a hue h in degrees, and a
+// saturation s and value v between 0 and 1.
+func HSV(h, s, v float64) int {
+	s, v = clamp(s), clamp(v)
+	c := v * s
+	return hcm(h, c, v-c)
+}
+
+// hsl returns the hue in degrees, saturation and lightness of an
+// sRGB color
+func hsl(r, g, b uint8) (h, s, l float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	max, min := math.Max(rf, math.Max(gf, bf)), math.Min(rf, math.Min(gf, bf))
+	l = (max + min) / 2
+	c := max - min
+	if c == 0 {
+		return 0, 0, l
+	}
+	s = c / (1 - math.Abs(2*l-1))
+	switch max {
+	case rf:
+		h = 60 * math.Mod((gf-bf)/c+6, 6)
+	case gf:
+		h = 60 * ((bf-rf)/c + 2)
+	default:
+		h = 60 * ((rf-gf)/c + 4)
+	}
+	return h, s, l
+}
+
+// hcm converts a hue in degrees, a chroma and a lightness offset
+// to a truecolor code
+func hcm(h, c, m float64) int {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return RGB(unit(r+m), unit(g+m), unit(b+m))
+}
+
+// clamp limits f to the range [0, 1]
+func clamp(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// unit converts f in the range [0, 1] to a color component
+func unit(f float64) uint8 {
+	return uint8(math.Round(clamp(f) * 255))
+}
+
+// hex returns the "#rrggbb" form of a truecolor code
+func hex(c int) string {
+	r, g, b := rgb(c)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// rgb returns the components of a truecolor code
+func rgb(c int) (r, g, b uint8) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c)
+}
+
+// underlineCode returns the ECMA-48 parameters selecting the color c as
+// the underline color (SGR 58). The underline color can't be selected
+// from the 16-color palette, so nothing is returned below Depth256.
+func underlineCode(c int, d Depth) string {
+	if !ValidColor(c) || d < Depth256 {
+		return ""
+	}
+	switch c = Degrade(c, d); {
+	case c == Default:
+		return "59"
+	case c >= Black && c <= White:
+		c = Color256(uint8(c - Black))
+	case c >= BrightBlack && c <= BrightWhite:
+		c = Color256(uint8(c - BrightBlack + 8))
+	}
+	return "58" + strings.TrimPrefix(colorCode(c, false), "38")
+}
+
+// ValidColor reports whether c is a color code: one of the color
+// constants, or a code returned by Color256 or RGB.
+func ValidColor(c int) bool {
+	switch c & tagMask {
+	case tag256:
+		return c&^tagMask <= 0xff
+	case tagRGB:
+		return true
+	case 0:
+		return c >= Black && c <= White || c == Default || c >= BrightBlack && c <= BrightWhite
+	}
+	return false
+}
+
+// normalize converts an ECMA-48 background color code to the matching
+// foreground color code. Other codes are returned unchanged.
+func normalize(c int) int {
+	if c >= Black+10 && c <= White+10 || c == Default+10 || c >= BrightBlack+10 && c <= BrightWhite+10 {
+		return c - 10
+	}
+	return c
+}
+
+// colorCode returns the ECMA-48 parameters selecting the color c as a
+// foreground color, or as a background color if bg is true.
+func colorCode(c int, bg bool) string {
+	switch c & tagMask {
+	case tag256:
+		if bg {
+			return "48;5;" + strconv.Itoa(c&0xff)
+		}
+		return "38;5;" + strconv.Itoa(c&0xff)
+	case tagRGB:
+		r, g, b := rgb(c)
+		p := "38;2;"
+		if bg {
+			p = "48;2;"
+		}
+		return p + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b))
+	}
+	if bg {
+		return strconv.Itoa(c + 10)
+	}
+	return strconv.Itoa(c)
+}
@@ -0,0 +1,109 @@
+package hue
+
+import "testing"
+
+func TestColor256(t *testing.T) {
+	hs := Encode(New(Color256(208), Color256(17)), "x")
+	if want := String("\033[38;5;208;48;5;17mx\033[0m"); hs != want {
+		t.Errorf("have %q, want %q", hs, want)
+	}
+	if s, err := hs.Decode(); s != "x" || err != nil {
+		t.Errorf("Decode: have %q, %v", s, err)
+	}
+}
+
+func TestRGB(t *testing.T) {
+	hs := Encode(New(RGB(255, 136, 0), RGB(0, 0, 1)), "x")
+	if want := String("\033[38;2;255;136;0;48;2;0;0;1mx\033[0m"); hs != want {
+		t.Errorf("have %q, want %q", hs, want)
+	}
+	if r, g, b := rgb(RGB(1, 2, 3)); r != 1 || g != 2 || b != 3 {
+		t.Errorf("rgb: have %d %d %d", r, g, b)
+	}
+}
+
+func TestHex(t *testing.T) {
+	for s, want := range map[string]int{
+		"#ff8800": RGB(0xff, 0x88, 0),
+		"FF8800":  RGB(0xff, 0x88, 0),
+		"#f80":    RGB(0xff, 0x88, 0),
+	} {
+		if c, err := Hex(s); err != nil || c != want {
+			t.Errorf("Hex(%q): have %x, %v", s, c, err)
+		}
+	}
+	for _, s := range []string{"", "#ff88", "#gg8800", "#ff88000"} {
+		if _, err := Hex(s); err == nil {
+			t.Errorf("Hex(%q): expected an error", s)
+		}
+	}
+}
+
+func TestColorByName(t *testing.T) {
+	for name, want := range map[string]int{
+		"DodgerBlue": RGB(0x1e, 0x90, 0xff),
+		"coral":      RGB(0xff, 0x7f, 0x50),
+		"red":        Red,
+		"#010203":    RGB(1, 2, 3),
+	} {
+		if c, ok := ColorByName(name); !ok || c != want {
+			t.Errorf("ColorByName(%q): have %x, %v", name, c, ok)
+		}
+	}
+	if _, ok := ColorByName("notacolor"); ok {
+		t.Errorf("ColorByName: found a color for an unknown name")
+	}
+}
+
+func TestHSLHSV(t *testing.T) {
+	for _, tc := range []struct {
+		have, want int
+	}{
+		{HSL(0, 1, 0.5), RGB(255, 0, 0)},
+		{HSL(120, 1, 0.25), RGB(0, 128, 0)},
+		{HSL(240, 1, 0.5), RGB(0, 0, 255)},
+		{HSL(-60, 1, 0.5), RGB(255, 0, 255)},
+		{HSL(0, 0, 1), RGB(255, 255, 255)},
+		{HSV(60, 1, 1), RGB(255, 255, 0)},
+		{HSV(210, 0.5, 1), RGB(128, 191, 255)},
+		{HSV(0, 0, 0), RGB(0, 0, 0)},
+	} {
+		if tc.have != tc.want {
+			t.Errorf("have %s, want %s", hex(tc.have), hex(tc.want))
+		}
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	for _, c := range []int{Black, White, Default, BrightBlack, BrightWhite, Color256(255), RGB(1, 2, 3)} {
+		if !ValidColor(c) {
+			t.Errorf("ValidColor(%x): have false", c)
+		}
+	}
+	for _, c := range []int{0, 1, 38, 41, 49, 98, 107, tag256 | 0x100, 3 << 24, -1} {
+		if ValidColor(c) {
+			t.Errorf("ValidColor(%x): have true", c)
+		}
+	}
+}
+
+func TestBackgroundCodes(t *testing.T) {
+	if h := New(Red, 41); h.Bg != Red {
+		t.Errorf("SetBg(41): have %d", h.Bg)
+	}
+	if h := New(BrightWhite, 107); h.Bg != BrightWhite {
+		t.Errorf("SetBg(107): have %d", h.Bg)
+	}
+	if _, err := NewChecked(Red, 49); err != nil {
+		t.Errorf("NewChecked: %v", err)
+	}
+	if _, err := NewChecked(Red, 12); err == nil {
+		t.Errorf("NewChecked: expected an error for background 12")
+	}
+	if _, err := NewChecked(99, Red); err == nil {
+		t.Errorf("NewChecked: expected an error for foreground 99")
+	}
+	if have, want := Encode(&Hue{Fg: 12, Bg: 44}, "x"), String("\033[44mx\033[0m"); have != want {
+		t.Errorf("invalid colors: have %q, want %q", have, want)
+	}
+}
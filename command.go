@@ -0,0 +1,58 @@
+package hue
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// Command colors the standard output and standard error of cmd with
+// the hues stdout and stderr, before it is started. The streams go to
+// cmd.Stdout and cmd.Stderr if they are set, or to os.Stdout and
+// os.Stderr. Output is written a line at a time, and lines from the
+// two streams never interleave. A nil hue leaves its stream uncolored.
+//
+// The returned function writes the last line of each stream if it has
+// no newline; call it after cmd.Wait:
+//
+//	cmd := exec.Command("go", "build", "./...")
+//	flush := hue.Command(cmd, nil, hue.New(hue.Red, 0))
+//	err := cmd.Run()
+//	flush()
+func Command(cmd *exec.Cmd, stdout, stderr *Hue) (flush func() error) {
+	mu := new(sync.Mutex)
+	out := commandWriter(mu, cmd.Stdout, os.Stdout, stdout)
+	errw := commandWriter(mu, cmd.Stderr, os.Stderr, stderr)
+	cmd.Stdout, cmd.Stderr = out, errw
+	return func() error {
+		err := out.Flush()
+		if err2 := errw.Flush(); err == nil {
+			err = err2
+		}
+		return err
+	}
+}
+
+// commandWriter returns a LineWriter coloring the lines written to it
+// with h and writing them to dst, or def if dst is nil, while holding mu
+func commandWriter(mu *sync.Mutex, dst, def io.Writer, h *Hue) *LineWriter {
+	if dst == nil {
+		dst = def
+	}
+	w := NewLineWriter(&lockedWriter{mu, dst}, func([]byte) *Hue { return h })
+	w.color = detect(dst)
+	return w
+}
+
+// lockedWriter holds a mutex, which may be shared, during each write
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,31 @@
+package hue
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh")
+	}
+	var out, errb bytes.Buffer
+	cmd := exec.Command(sh, "-c", "echo out; echo err >&2; printf tail")
+	cmd.Stdout, cmd.Stderr = &out, &errb
+	red := New(Red, 0)
+	flush := Command(cmd, nil, red)
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := flush(); err != nil {
+		t.Fatal(err)
+	}
+	if have := out.String(); have != "out\ntail" {
+		t.Errorf("stdout: have %q", have)
+	}
+	if have, want := errb.String(), string(Encode(red, "err"))+"\n"; have != want {
+		t.Errorf("stderr: have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,35 @@
+package hue
+
+import "math"
+
+// Contrast returns the WCAG 2 contrast ratio between the colors fg and
+// bg, from 1 for identical colors to 21 for black on white. WCAG asks
+// for at least 4.5 for body text. Contrast returns 0 if either color
+// has no RGB value, as is the case for Default.
+func Contrast(fg, bg int) float64 {
+	r1, g1, b1, ok1 := toRGB(fg)
+	r2, g2, b2, ok2 := toRGB(bg)
+	if !ok1 || !ok2 {
+		return 0
+	}
+	l1, l2 := luminance(r1, g1, b1), luminance(r2, g2, b2)
+	return (math.Max(l1, l2) + 0.05) / (math.Min(l1, l2) + 0.05)
+}
+
+// AutoFg returns a readable foreground color for the background color
+// bg: Black or BrightWhite, whichever contrasts more. It returns Default
+// if bg has no RGB value.
+func AutoFg(bg int) int {
+	if _, _, _, ok := toRGB(bg); !ok {
+		return Default
+	}
+	if Contrast(Black, bg) >= Contrast(BrightWhite, bg) {
+		return Black
+	}
+	return BrightWhite
+}
+
+// luminance returns the WCAG relative luminance of an sRGB color
+func luminance(r, g, b uint8) float64 {
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
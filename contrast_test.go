@@ -0,0 +1,39 @@
+package hue
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrast(t *testing.T) {
+	for _, tc := range []struct {
+		fg, bg int
+		want   float64
+	}{
+		{RGB(0, 0, 0), RGB(255, 255, 255), 21},
+		{RGB(255, 255, 255), RGB(0, 0, 0), 21},
+		{Red, Red, 1},
+		{RGB(0x77, 0x77, 0x77), RGB(255, 255, 255), 4.48},
+		{Default, White, 0},
+	} {
+		if have := Contrast(tc.fg, tc.bg); math.Abs(have-tc.want) > 0.01 {
+			t.Errorf("Contrast(%x, %x): have %.2f, want %.2f", tc.fg, tc.bg, have, tc.want)
+		}
+	}
+}
+
+func TestAutoFg(t *testing.T) {
+	for bg, want := range map[int]int{
+		White:             Black,
+		Brown:             Black,
+		Blue:              BrightWhite,
+		RGB(20, 20, 60):   BrightWhite,
+		CSSColors["gold"]: Black,
+		Color256(232):     BrightWhite,
+		Default:           Default,
+	} {
+		if have := AutoFg(bg); have != want {
+			t.Errorf("AutoFg(%x): have %d, want %d", bg, have, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package hue
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Depth is the range of colors a terminal can display. Colors a
+// terminal can't display are converted to the nearest color it can.
+type Depth int32
+
+const (
+	DepthNone Depth = iota // No colors
+	Depth16                // The basic and bright colors
+	Depth256               // The 256-color palette
+	DepthTrue              // 24-bit truecolor
+)
+
+// depth is the program-wide color depth
+var depth atomic.Int32
+
+// SetDepth sets the program-wide color depth used by Encode, the Hue
+// output functions and the writers. The initial depth is detected by
+// LoadEnv.
+func SetDepth(d Depth) {
+	depth.Store(int32(d))
+}
+
+// CurrentDepth returns the program-wide color depth
+func CurrentDepth() Depth {
+	return Depth(depth.Load())
+}
+
+// envDepth returns the color depth advertised by COLORTERM and TERM.
+// A dumb terminal has no colors, and one that advertises nothing is
+// assumed to have the basic 16.
+func envDepth() Depth {
+	term := os.Getenv("TERM")
+	if term == "dumb" {
+		return DepthNone
+	}
+	switch ct := os.Getenv("COLORTERM"); {
+	case ct == "truecolor" || ct == "24bit":
+		return DepthTrue
+	}
+	switch {
+	case strings.Contains(term, "256color"):
+		return Depth256
+	}
+	return Depth16
+}
+
+// Degrade returns the color nearest to c that can be displayed at the
+// depth d. Colors that can already be displayed are returned unchanged.
+// At DepthNone, Degrade returns 0, which leaves a hue's color unset.
+func Degrade(c int, d Depth) int {
+	switch {
+	case d == DepthNone:
+		return 0
+	case d >= DepthTrue:
+		return c
+	}
+	switch c & tagMask {
+	case tagRGB:
+		if d == Depth256 {
+			return Color256(nearest256(rgb(c)))
+		}
+		return basic(nearest16(rgb(c)))
+	case tag256:
+		n := uint8(c)
+		if d == Depth256 {
+			return c
+		}
+		if n < 16 {
+			return basic(n)
+		}
+		return basic(nearest16(paletteRGB(n)))
+	}
+	return c
+}
+
+// basic returns the color code for entry n of the 16-color palette
+func basic(n uint8) int {
+	if n < 8 {
+		return Black + int(n)
+	}
+	return BrightBlack + int(n) - 8
+}
+
+// cube holds the component levels of the 256-color palette's color cube
+var cube = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ansi16 holds the xterm default values of the 16-color palette
+var ansi16 = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// paletteRGB returns the components of entry n of the 256-color palette
+func paletteRGB(n uint8) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		c := ansi16[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return cube[n/36], cube[n/6%6], cube[n%6]
+	}
+	v := 8 + 10*(n-232)
+	return v, v, v
+}
+
+// nearest256 returns the entry of the 256-color palette's color cube or
+// grayscale ramp nearest to the components r, g and b
+func nearest256(r, g, b uint8) uint8 {
+	level := func(v uint8) uint8 {
+		var best uint8
+		for i := range cube {
+			if absDiff(v, cube[i]) < absDiff(v, cube[best]) {
+				best = uint8(i)
+			}
+		}
+		return best
+	}
+	ci := 16 + 36*level(r) + 6*level(g) + level(b)
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	gi := 232 + uint8(min((avg-3)/10, 23))
+
+	cr, cg, cb := paletteRGB(ci)
+	gr, gg, gb := paletteRGB(gi)
+	if distance(r, g, b, gr, gg, gb) < distance(r, g, b, cr, cg, cb) {
+		return gi
+	}
+	return ci
+}
+
+// nearest16 returns the entry of the 16-color palette nearest to the
+// components r, g and b
+func nearest16(r, g, b uint8) uint8 {
+	var best uint8
+	for i := range ansi16 {
+		c, d := ansi16[i], ansi16[best]
+		if distance(r, g, b, c[0], c[1], c[2]) < distance(r, g, b, d[0], d[1], d[2]) {
+			best = uint8(i)
+		}
+	}
+	return best
+}
+
+// distance returns a perceptually weighted squared distance between
+// two colors
+func distance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return 2*dr*dr + 4*dg*dg + 3*db*db
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
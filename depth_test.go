@@ -0,0 +1,54 @@
+package hue
+
+import "testing"
+
+func TestDegrade(t *testing.T) {
+	for _, tc := range []struct {
+		c    int
+		d    Depth
+		want int
+	}{
+		{RGB(1, 2, 3), DepthTrue, RGB(1, 2, 3)},
+		{RGB(255, 0, 0), Depth256, Color256(196)},
+		{RGB(128, 128, 128), Depth256, Color256(244)},
+		{RGB(250, 10, 10), Depth16, BrightRed},
+		{RGB(0, 0, 0), Depth16, Black},
+		{Color256(9), Depth16, BrightRed},
+		{Color256(2), Depth16, Green},
+		{Color256(226), Depth16, BrightBrown},
+		{Red, Depth16, Red},
+		{Default, Depth256, Default},
+		{Red, DepthNone, 0},
+	} {
+		if have := Degrade(tc.c, tc.d); have != tc.want {
+			t.Errorf("Degrade(%x, %d): have %x, want %x", tc.c, tc.d, have, tc.want)
+		}
+	}
+}
+
+func TestEncodeDepth(t *testing.T) {
+	defer SetDepth(DepthTrue)
+	SetDepth(Depth16)
+	if have, want := Encode(New(RGB(255, 0, 0), Color256(0)), "x"), String("\033[91;40mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestEnvDepth(t *testing.T) {
+	for _, tc := range []struct {
+		term, colorterm string
+		want            Depth
+	}{
+		{"", "", Depth16},
+		{"dumb", "truecolor", DepthNone},
+		{"xterm", "", Depth16},
+		{"xterm-256color", "", Depth256},
+		{"xterm", "24bit", DepthTrue},
+	} {
+		t.Setenv("TERM", tc.term)
+		t.Setenv("COLORTERM", tc.colorterm)
+		if have := envDepth(); have != tc.want {
+			t.Errorf("TERM=%q COLORTERM=%q: have %d, want %d", tc.term, tc.colorterm, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package hue
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// disabled is set when colorization is turned off for the whole program
+var disabled atomic.Bool
+
+// forced is set when writers should colorize output to files that are
+// not terminals
+var forced atomic.Bool
+
+func init() {
+	LoadEnv()
+}
+
+// LoadEnv sets the program-wide colorization settings from the
+// environment. It is called during package initialization; Enable,
+// Disable and SetDepth may be called afterwards to override the result.
+//
+// A non-empty NO_COLOR disables colorization. Otherwise, a
+// CLICOLOR_FORCE other than "" or "0" enables it, whatever CLICOLOR
+// and TERM say, and calls ForceColor; failing that, a CLICOLOR of "0"
+// disables it.
+// See https://no-color.org and https://bixense.com/clicolors.
+//
+// The color depth is none if TERM is "dumb", truecolor if COLORTERM is
+// "truecolor" or "24bit", 256 colors if TERM mentions "256color", and
+// 16 colors otherwise, including without a TERM. Forced colors are at
+// least 16.
+func LoadEnv() {
+	force := os.Getenv("CLICOLOR_FORCE")
+	ForceColor(force != "" && force != "0")
+	noColor := os.Getenv("NO_COLOR") != ""
+	d := envDepth()
+	if forced.Load() && !noColor && d == DepthNone {
+		d = Depth16
+	}
+	SetDepth(d)
+	switch {
+	case noColor:
+		Disable()
+	case forced.Load():
+		Enable()
+	case os.Getenv("CLICOLOR") == "0":
+		Disable()
+	}
+}
+
+// Enable turns colorization on. It is on by default.
+func Enable() {
+	disabled.Store(false)
+}
+
+// Disable turns colorization off. Encode, the Hue output functions,
+// Writer and RegexpWriter then emit plain text unless a writer
+// overrides the setting with SetEnabled.
+func Disable() {
+	disabled.Store(true)
+}
+
+// Enabled reports whether colorization is on
+func Enabled() bool {
+	return !disabled.Load()
+}
+
+// ForceColor sets whether writers colorize output to files that are not
+// terminals. By default, a Writer or RegexpWriter created for an
+// *os.File that is not a terminal, such as a redirected os.Stdout,
+// writes plain text. Disable still takes precedence.
+func ForceColor(on bool) {
+	forced.Store(on)
+}
+
+// isTerminal reports whether w is a file attached to a terminal or
+// a writer that isn't a file at all, which is left to the caller
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// detect returns the toggle for a writer writing to w
+func detect(w io.Writer) toggle {
+	if isTerminal(w) {
+		return inherit
+	}
+	return notTerminal
+}
+
+// toggle is a writer's override of the program-wide setting
+type toggle int8
+
+const (
+	inherit toggle = iota
+	forceOn
+	forceOff
+	notTerminal // inherit, unless ForceColor is off
+)
+
+// set overrides the program-wide setting
+func (t *toggle) set(on bool) {
+	if on {
+		*t = forceOn
+	} else {
+		*t = forceOff
+	}
+}
+
+// on reports whether colors should be emitted
+func (t toggle) on() bool {
+	switch t {
+	case forceOn:
+		return true
+	case forceOff:
+		return false
+	case notTerminal:
+		return forced.Load() && Enabled()
+	}
+	return Enabled()
+}
+
+// depth returns the color depth to emit, which is DepthNone if colors
+// should not be emitted
+func (t toggle) depth() Depth {
+	if !t.on() {
+		return DepthNone
+	}
+	return CurrentDepth()
+}
@@ -0,0 +1,97 @@
+package hue
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestMain ignores the color settings of the environment running the tests
+func TestMain(m *testing.M) {
+	Enable()
+	ForceColor(false)
+	SetDepth(DepthTrue)
+	os.Exit(m.Run())
+}
+
+func TestDisable(t *testing.T) {
+	h := New(Red, Default)
+	Disable()
+	defer Enable()
+
+	if have := Encode(h, "x"); have != "x" {
+		t.Errorf("Encode: have %q", have)
+	}
+
+	var b bytes.Buffer
+	w := NewWriter(&b, h)
+	w.WriteString("a")
+	w.SetEnabled(true)
+	w.WriteString("b")
+	if have, want := b.String(), "a"+string(h.encode(DepthTrue, "b")); have != want {
+		t.Errorf("Writer: have %q, want %q", have, want)
+	}
+
+	b.Reset()
+	re := NewRegexpWriter(&b)
+	re.AddRuleString(h, "x")
+	re.WriteString("axb")
+	if have := b.String(); have != "axb" {
+		t.Errorf("RegexpWriter: have %q", have)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	defer Enable()
+	defer ForceColor(false)
+	defer SetDepth(DepthTrue)
+	for _, tc := range []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{}, true},
+		{map[string]string{"NO_COLOR": "1"}, false},
+		{map[string]string{"CLICOLOR": "0"}, false},
+		{map[string]string{"CLICOLOR": "1"}, true},
+		{map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, false},
+		{map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "1"}, true},
+	} {
+		Enable()
+		for _, k := range []string{"NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE"} {
+			t.Setenv(k, tc.env[k])
+		}
+		LoadEnv()
+		if Enabled() != tc.want {
+			t.Errorf("%v: have Enabled() == %v", tc.env, Enabled())
+		}
+	}
+
+	t.Setenv("TERM", "dumb")
+	t.Setenv("CLICOLOR_FORCE", "1")
+	LoadEnv()
+	if d := CurrentDepth(); d != Depth16 {
+		t.Errorf("forced on a dumb terminal: have depth %d", d)
+	}
+}
+
+func TestNotTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "hue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := New(Red, 0)
+
+	NewWriter(f, h).WriteString("a")
+	ForceColor(true)
+	defer ForceColor(false)
+	NewWriter(f, h).WriteString("b")
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(data), "a"+string(Encode(h, "b")); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,34 @@
+package hue
+
+import "fmt"
+
+// Error is an error value whose message is colorized with a hue.
+// Error returns the colored message; Plain returns the message
+// without color codes for sinks that are not terminals.
+type Error struct {
+	h   *Hue
+	err error
+}
+
+// Errorf behaves like fmt.Errorf, except the returned error's message
+// is colorized. The %w verb is supported and the wrapped error is
+// available to errors.Is and errors.As.
+func (h *Hue) Errorf(format string, a ...interface{}) error {
+	return &Error{h: h, err: fmt.Errorf(format, a...)}
+}
+
+// Error returns the colorized error message
+func (e *Error) Error() string {
+	return string(e.h.Sprint(e.err.Error()))
+}
+
+// Plain returns the error message without color codes
+func (e *Error) Plain() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the uncolored error created by Errorf. Errors wrapped
+// with %w are reachable through it.
+func (e *Error) Unwrap() error {
+	return e.err
+}
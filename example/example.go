@@ -16,7 +16,9 @@ func main() {
 	green := hue.New(hue.Green, hue.Default)
 	blue := hue.New(hue.Blue, hue.Default)
 	yellow := hue.New(hue.Brown, hue.Default)
-	magenta := hue.New(hue.Magenta, hue.Default)
+
+	// A hue can also be created with a composite literal
+	magenta := &hue.Hue{Fg: hue.Magenta, Bg: hue.Default}
 
 	// Print the red string with a hue object
 	red.Println("Red")
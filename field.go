@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+)
+
+// FieldWriter colors the fields of each line, awk style, with a hue
+// for each column. For example, to color the level and the message of
+// space-separated log lines:
+//
+//	w := hue.NewFieldWriter(os.Stdout, "", map[int]*hue.Hue{
+//		3: hue.New(hue.Brown, 0),
+//		4: hue.New(hue.Cyan, 0),
+//	})
+//
+// A FieldWriter is a LineWriter; call Flush to write a last line
+// without a newline.
+type FieldWriter struct {
+	*LineWriter
+	sep  []byte
+	cols map[int]*Hue
+}
+
+// NewFieldWriter returns a FieldWriter that writes to w. Fields are
+// separated by sep or, if sep is empty, by runs of spaces and tabs, as
+// in awk. Columns are numbered from 1; those missing from cols are
+// written uncolored, as are the separators.
+func NewFieldWriter(w io.Writer, sep string, cols map[int]*Hue) *FieldWriter {
+	n := &FieldWriter{LineWriter: NewLineWriter(w, nil), sep: []byte(sep), cols: cols}
+	n.format = n.fields
+	return n
+}
+
+// fields colors the fields of line, a subslice of data
+func (w *FieldWriter) fields(d Depth, data, line []byte) {
+	col := 1
+	for len(line) > 0 {
+		var field, sep []byte
+		if len(w.sep) == 0 {
+			i := bytes.IndexFunc(line, notBlank)
+			if i < 0 {
+				i = len(line)
+			}
+			w.out.copy(data, line[:i])
+			line = line[i:]
+			field = line
+			if i := bytes.IndexAny(line, " \t"); i >= 0 {
+				field = line[:i]
+			}
+		} else {
+			field = line
+			if i := bytes.Index(line, w.sep); i >= 0 {
+				field, sep = line[:i], line[i:i+len(w.sep)]
+			}
+		}
+		w.paint(d, data, field, w.cols[col])
+		w.out.copy(data, sep)
+		line = line[len(field)+len(sep):]
+		col++
+	}
+}
+
+// notBlank reports whether r is something other than a space or tab
+func notBlank(r rune) bool {
+	return r != ' ' && r != '\t'
+}
@@ -0,0 +1,33 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFieldWriter(t *testing.T) {
+	red, blue := New(Red, 0), New(Blue, 0)
+	cols := map[int]*Hue{2: red, 3: blue}
+	for _, tc := range []struct {
+		sep, in, want string
+	}{
+		{"", "  a  b\tc d\n", "  a  " + string(Encode(red, "b")) + "\t" + string(Encode(blue, "c")) + " d\n"},
+		{",", "a,,c\n", "a,," + string(Encode(blue, "c")) + "\n"},
+	} {
+		var b bytes.Buffer
+		w := NewFieldWriter(&b, tc.sep, cols)
+		w.WriteString(tc.in)
+		if have := b.String(); have != tc.want {
+			t.Errorf("%q: have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+}
+
+func TestFieldWriterCount(t *testing.T) {
+	sw := &shortWriter{n: 9}
+	w := NewFieldWriter(sw, ",", nil)
+	n, err := w.WriteString("aaaa\nbb\ncc\n")
+	if n != 9 || err == nil {
+		t.Errorf("short write: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,65 @@
+package hue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Flag is a flag.Value holding a single hue. The flag's value is a
+// spec accepted by Parse:
+//
+//	var color hue.Flag
+//	flag.Var(&color, "color", "output `color`")
+type Flag struct {
+	Hue
+}
+
+// Set parses s with Parse and stores the result
+func (f *Flag) Set(s string) error {
+	return f.UnmarshalText([]byte(s))
+}
+
+// String returns the hue's spec
+func (f *Flag) String() string {
+	b, _ := f.MarshalText()
+	return string(b)
+}
+
+// FlagMap is a flag.Value mapping names to hues. The flag's value is a
+// comma-separated list of name=spec pairs, such as "error=red,warn=brown".
+// Setting the flag more than once adds to the map.
+type FlagMap map[string]*Hue
+
+// Set parses the name=spec pairs in s and adds them to the map
+func (m *FlagMap) Set(s string) error {
+	if *m == nil {
+		*m = make(FlagMap)
+	}
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.IndexByte(pair, '=')
+		if i < 0 {
+			return fmt.Errorf("hue: bad flag value %q: expected name=spec", pair)
+		}
+		h, err := Parse(pair[i+1:])
+		if err != nil {
+			return err
+		}
+		(*m)[strings.TrimSpace(pair[:i])] = h
+	}
+	return nil
+}
+
+// String returns the map's name=spec pairs sorted by name
+func (m *FlagMap) String() string {
+	if m == nil {
+		return ""
+	}
+	var pairs []string
+	for k, h := range *m {
+		b, _ := h.MarshalText()
+		pairs = append(pairs, k+"="+string(b))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
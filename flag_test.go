@@ -0,0 +1,30 @@
+package hue
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	var (
+		color  Flag
+		colors FlagMap
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&color, "color", "")
+	fs.Var(&colors, "colors", "")
+
+	err := fs.Parse([]string{"-color", "red/white", "-colors", "error=red,warn=brown", "-colors", "ok=green"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if color.Hue != (Hue{Fg: Red, Bg: White}) {
+		t.Errorf("color: have %+v", color.Hue)
+	}
+	if have, want := colors.String(), "error=red,ok=green,warn=brown"; have != want {
+		t.Errorf("colors: have %q, want %q", have, want)
+	}
+	if err := colors.Set("error"); err == nil {
+		t.Errorf("expected an error for a pair without a spec")
+	}
+}
@@ -0,0 +1,70 @@
+package hue
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Value is a value that is colorized when formatted by the fmt package.
+// It is created with Wrap.
+type Value struct {
+	h *Hue
+	v interface{}
+}
+
+// Wrap returns v wrapped so that formatting it with the fmt package
+// colorizes just that value. Flags, width and precision are applied to
+// v as usual:
+//
+//	fmt.Printf("%-10v %5.1f\n", hue.Wrap(red, name), hue.Wrap(green, score))
+func Wrap(h *Hue, v interface{}) Value {
+	return Value{h, v}
+}
+
+// Format implements fmt.Formatter
+func (v Value) Format(f fmt.State, verb rune) {
+	io.WriteString(f, string(v.h.encode(inherit.depth(), fmt.Sprintf(directive(f, verb), v.v))))
+}
+
+// directive rebuilds the formatting directive that f and verb were
+// parsed from
+func directive(f fmt.State, verb rune) string {
+	b := []byte{'%'}
+	for _, c := range "+-# 0" {
+		if f.Flag(int(c)) {
+			b = append(b, byte(c))
+		}
+	}
+	if w, ok := f.Width(); ok {
+		b = strconv.AppendInt(b, int64(w), 10)
+	}
+	if p, ok := f.Precision(); ok {
+		b = append(b, '.')
+		b = strconv.AppendInt(b, int64(p), 10)
+	}
+	return string(append(b, string(verb)...))
+}
+
+// Arg is a printf argument carrying its own hue. A nil Hue leaves the
+// value uncolored.
+type Arg struct {
+	Hue   *Hue
+	Value interface{}
+}
+
+// Colorf behaves like fmt.Sprintf, except each argument is colorized
+// with its own hue:
+//
+//	hue.Colorf("%s=%d", hue.Arg{Hue: key, Value: "retries"}, hue.Arg{Hue: val, Value: 3})
+func Colorf(format string, args ...Arg) String {
+	a := make([]interface{}, len(args))
+	for i, v := range args {
+		if v.Hue == nil {
+			a[i] = v.Value
+		} else {
+			a[i] = Wrap(v.Hue, v.Value)
+		}
+	}
+	return String(fmt.Sprintf(format, a...))
+}
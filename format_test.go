@@ -0,0 +1,40 @@
+package hue
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	red := New(Red, 0)
+	for _, tc := range []struct {
+		format string
+		v      interface{}
+		want   string
+	}{
+		{"%v", "x", "x"},
+		{"%-4s|", "x", "x   "},
+		{"%5.1f", 3.14159, "  3.1"},
+		{"%+d", 3, "+3"},
+		{"%#x", 255, "0xff"},
+		{"%q", "a", `"a"`},
+	} {
+		have := fmt.Sprintf(tc.format, Wrap(red, tc.v))
+		want := string(Encode(red, tc.want))
+		if tc.format == "%-4s|" {
+			want += "|"
+		}
+		if have != want {
+			t.Errorf("Sprintf(%q): have %q, want %q", tc.format, have, want)
+		}
+	}
+}
+
+func TestColorf(t *testing.T) {
+	key, val := New(Cyan, 0), New(Brown, 0)
+	have := Colorf("%s=%d %v", Arg{key, "retries"}, Arg{val, 3}, Arg{nil, "ok"})
+	want := key.Sprint("retries") + "=" + val.Sprint(3) + " ok"
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,342 @@
+package hue
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ToHTML converts colored text to HTML, replacing the SGR sequences
+// with <span> elements styled inline, as with an HTMLWriter. The text
+// is escaped, and its newlines are kept, so the result suits a <pre>
+// element.
+func ToHTML(s string) string {
+	var b strings.Builder
+	w := NewHTMLWriter(&b)
+	w.WriteString(s)
+	w.Close()
+	return b.String()
+}
+
+// HTMLWriter converts the colored text written through it to HTML
+// and writes that to the underlying writer. Each run of text in a
+// style is put in a <span> with the style as CSS, such as
+//
+//	<span style="color:#cd0000;font-weight:bold">error</span>
+//
+// Basic and 256-palette colors are written as the xterm defaults,
+// and reverse video as the colors swapped, taking the default colors
+// to be black text on white. Escape sequences other than SGR, such as
+// hyperlinks, are dropped. Call Close at the end of the text to close
+// the last <span>. An HTMLWriter is safe for concurrent use.
+type HTMLWriter struct {
+	mu      sync.Mutex
+	wrapped io.Writer
+	lex     lexer
+	style   Hue  // the style selected by the input
+	cur     Hue  // the style of the open span
+	open    bool // a span is open
+	out     []byte
+}
+
+// NewHTMLWriter returns an HTMLWriter that writes HTML to w
+func NewHTMLWriter(w io.Writer) *HTMLWriter {
+	return &HTMLWriter{wrapped: w}
+}
+
+// Write converts p to HTML and writes it to the underlying writer. A
+// sequence may be split across writes. Since p and the HTML differ in
+// length, n is 0 if the underlying writer fails.
+func (w *HTMLWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out = w.out[:0]
+	w.lex.feed(p, func(b []byte, esc bool) {
+		if esc {
+			if params, ok := sgrParams(b); ok {
+				w.style.apply(params)
+			}
+			return
+		}
+		if !w.open || w.cur != w.style {
+			w.span(w.style)
+		}
+		w.out = append(w.out, html.EscapeString(string(b))...)
+	})
+	if err := w.flush(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *HTMLWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Close closes the open <span>, if there is one. The underlying writer
+// is not closed.
+func (w *HTMLWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out = w.out[:0]
+	w.span(Hue{})
+	return w.flush()
+}
+
+// span ends the open span and starts one for the style h, if it has
+// any. The caller holds w.mu.
+func (w *HTMLWriter) span(h Hue) {
+	if w.open {
+		w.out = append(w.out, "</span>"...)
+		w.open = false
+	}
+	w.cur = h
+	if css := h.css(); css != "" {
+		w.out = append(w.out, `<span style="`+css+`">`...)
+		w.open = true
+	}
+}
+
+// flush writes the pending output. The caller holds w.mu.
+func (w *HTMLWriter) flush() error {
+	if len(w.out) == 0 {
+		return nil
+	}
+	_, err := w.wrapped.Write(w.out)
+	return err
+}
+
+// css returns the CSS declarations displaying text in the hue, or ""
+// if it has none
+func (h Hue) css() string {
+	var p []string
+	fg, ok := cssColor(h.Fg)
+	bg, bgok := cssColor(h.Bg)
+	if h.Attr&Reverse != 0 {
+		// Swapped with the xterm defaults in place of unset colors
+		if !ok {
+			fg = "#000000"
+		}
+		if !bgok {
+			bg = "#ffffff"
+		}
+		fg, bg = bg, fg
+		ok, bgok = true, true
+	}
+	if ok {
+		p = append(p, "color:"+fg)
+	}
+	if bgok {
+		p = append(p, "background-color:"+bg)
+	}
+	if h.Attr&Bold != 0 {
+		p = append(p, "font-weight:bold")
+	}
+	if h.Attr&Dim != 0 {
+		p = append(p, "opacity:0.5")
+	}
+	if h.Attr&Italic != 0 {
+		p = append(p, "font-style:italic")
+	}
+	var deco []string
+	if h.Attr&Underline != 0 || h.UnderlineStyle != 0 {
+		deco = append(deco, "underline")
+	}
+	if h.Attr&Strike != 0 {
+		deco = append(deco, "line-through")
+	}
+	if deco != nil {
+		p = append(p, "text-decoration:"+strings.Join(deco, " "))
+	}
+	if h.UnderlineStyle > UnderlineSingle && int(h.UnderlineStyle) < len(cssUnderlines) {
+		p = append(p, "text-decoration-style:"+cssUnderlines[h.UnderlineStyle])
+	}
+	if c, ok := cssColor(h.UnderlineColor); ok {
+		p = append(p, "text-decoration-color:"+c)
+	}
+	if h.Attr&Conceal != 0 {
+		p = append(p, "visibility:hidden")
+	}
+	return strings.Join(p, ";")
+}
+
+// cssUnderlines holds the text-decoration-style of each underline style
+var cssUnderlines = [...]string{"", "solid", "double", "wavy", "dotted", "dashed"}
+
+// cssColor returns the "#rrggbb" form of the color c
+func cssColor(c int) (string, bool) {
+	r, g, b, ok := toRGB(c)
+	if !ok {
+		return "", false
+	}
+	return hex(RGB(r, g, b)), true
+}
+
+// FromHTML renders a small subset of HTML as colored text, at the
+// program-wide depth. The styles are taken from the elements b,
+// strong, i, em, u, s, strike and del, and from style attributes, such
+// as that of <span style="color:red">, using the CSS properties color,
+// background-color, font-weight: bold, font-style: italic and
+// text-decoration: underline or line-through. <br> starts a new line.
+// Other markup is left out, and its text is kept. Colors are CSS color
+// names, which mean the CSS colors rather than the terminal ones of the
+// same name, #rgb, #rrggbb or rgb(r, g, b). Entities are decoded.
+//
+// The HTML is parsed leniently: elements may be left open, and
+// attribute values unquoted. FromHTML returns the text colored so far
+// and an error if the HTML can't be parsed, as with a stray "<" or an
+// end tag that doesn't match.
+func FromHTML(s string) (String, error) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	depth := inherit.depth()
+
+	var (
+		b     strings.Builder
+		stack []Hue // the style inside each open element
+		cur   Hue   // the style of the text written last
+	)
+	top := func() Hue {
+		if len(stack) == 0 {
+			return Hue{}
+		}
+		return stack[len(stack)-1]
+	}
+	text := func(t string) {
+		if h := top(); h != cur && depth != DepthNone {
+			if h == (Hue{}) {
+				b.WriteString(ASCIIReset)
+			} else if c := transition(&cur, &h, depth); c != "" {
+				b.WriteString("\033[" + c + "m")
+			}
+			cur = h
+		}
+		b.WriteString(t)
+	}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF || err != nil && d.InputOffset() >= int64(len(s)) {
+			// The elements still open end with the text
+			break
+		}
+		if err != nil {
+			stack = nil
+			text("")
+			return String(b.String()), fmt.Errorf("hue: can't parse HTML: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(tok.Name.Local)
+			if name == "br" {
+				text("\n")
+				continue
+			}
+			h := top()
+			h.htmlElement(name, tok.Attr)
+			stack = append(stack, h)
+		case xml.EndElement:
+			if strings.ToLower(tok.Name.Local) != "br" && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text(string(tok))
+		}
+	}
+	stack = stack[:0]
+	text("")
+	return String(b.String()), nil
+}
+
+// htmlElement adds the style of the element name to h
+func (h *Hue) htmlElement(name string, attrs []xml.Attr) {
+	switch name {
+	case "b", "strong":
+		h.Attr |= Bold
+	case "i", "em":
+		h.Attr |= Italic
+	case "u":
+		h.Attr |= Underline
+	case "s", "strike", "del":
+		h.Attr |= Strike
+	}
+	for _, a := range attrs {
+		if strings.ToLower(a.Name.Local) == "style" {
+			h.htmlStyle(a.Value)
+		}
+	}
+}
+
+// htmlStyle adds the CSS declarations in style to h
+func (h *Hue) htmlStyle(style string) {
+	for _, decl := range strings.Split(style, ";") {
+		prop, val, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		val = strings.ToLower(strings.TrimSpace(val))
+		switch prop {
+		case "color":
+			if c, ok := parseCSSColor(val); ok {
+				h.Fg = c
+			}
+		case "background-color", "background":
+			if c, ok := parseCSSColor(val); ok {
+				h.Bg = c
+			}
+		case "font-weight":
+			if n, _ := strconv.Atoi(val); val == "bold" || val == "bolder" || n >= 600 {
+				h.Attr |= Bold
+			}
+		case "font-style":
+			if val == "italic" || val == "oblique" {
+				h.Attr |= Italic
+			}
+		case "text-decoration", "text-decoration-line":
+			for _, v := range strings.Fields(val) {
+				switch v {
+				case "underline":
+					h.Attr |= Underline
+				case "line-through":
+					h.Attr |= Strike
+				}
+			}
+		}
+	}
+}
+
+// parseCSSColor returns the color named by a CSS color value
+func parseCSSColor(val string) (int, bool) {
+	if inner, ok := strings.CutPrefix(val, "rgb("); ok {
+		f := strings.FieldsFunc(strings.TrimSuffix(inner, ")"), func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+		if len(f) != 3 {
+			return 0, false
+		}
+		var c [3]uint8
+		for i, s := range f {
+			n, err := strconv.ParseUint(s, 10, 8)
+			if err != nil {
+				return 0, false
+			}
+			c[i] = uint8(n)
+		}
+		return RGB(c[0], c[1], c[2]), true
+	}
+	if _, err := strconv.Atoi(val); err == nil {
+		return 0, false // a palette number, not a CSS color
+	}
+	if c, ok := CSSColors[val]; ok {
+		// Before the terminal colors, so brown is #a52a2a
+		return c, true
+	}
+	return colorByName(val)
+}
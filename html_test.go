@@ -0,0 +1,63 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"a < b", "a &lt; b"},
+		{"\033[1;31merror\033[0m: x", `<span style="color:#cd0000;font-weight:bold">error</span>: x`},
+		{"\033[38;5;208;48;2;1;2;3mx\033[39my\033[m",
+			`<span style="color:#ff8700;background-color:#010203">x</span>` +
+				`<span style="background-color:#010203">y</span>`},
+		{"\033[4:3;58;5;9;3mwavy", `<span style="font-style:italic;text-decoration:underline;` +
+			`text-decoration-style:wavy;text-decoration-color:#ff0000">wavy</span>`},
+		{"\033[31m\033]8;;http://x\033\\\033[32mgo\033[0m", `<span style="color:#00cd00">go</span>`},
+		{"\033[7mrev\033[31mred", `<span style="color:#ffffff;background-color:#000000">rev</span>` +
+			`<span style="color:#ffffff;background-color:#cd0000">red</span>`},
+	} {
+		if have := ToHTML(tc.in); have != tc.want {
+			t.Errorf("ToHTML(%q):\nhave %s\nwant %s", tc.in, have, tc.want)
+		}
+	}
+}
+
+func TestHTMLWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewHTMLWriter(&b)
+	w.WriteString("\033[3")
+	w.WriteString("4mblue\n")
+	w.WriteString("still")
+	w.Close()
+	if have, want := b.String(), `<span style="color:#0000ee">blue`+"\n"+`still</span>`; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestFromHTML(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want String
+	}{
+		{"plain &amp; simple", "plain & simple"},
+		{`<b>bold <span style="color: #ff0000; background-color: rgb(0, 0, 255)">red</span></b> x`,
+			"\033[1mbold \033[38;2;255;0;0;48;2;0;0;255mred" + ASCIIReset + " x"},
+		{`<i>a<br>b</i>`, "\033[3ma\nb" + ASCIIReset},
+		{`<p style="text-decoration: underline line-through">u</p>`, "\033[4;9mu" + ASCIIReset},
+		{`<span style="color:blue">unclosed`, "\033[38;2;0;0;255munclosed" + ASCIIReset},
+		{`<span style="color:Brown">b</span>`, "\033[38;2;165;42;42mb" + ASCIIReset},
+	} {
+		have, err := FromHTML(tc.in)
+		if err != nil {
+			t.Errorf("FromHTML(%q): %v", tc.in, err)
+		}
+		if have != tc.want {
+			t.Errorf("FromHTML(%q):\nhave %q\nwant %q", tc.in, have, tc.want)
+		}
+	}
+	if _, err := FromHTML("<b>a < b</b>"); err == nil {
+		t.Errorf("bad HTML: no error")
+	}
+}
@@ -5,9 +5,13 @@
 package hue
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Foreground color codes
@@ -20,8 +24,43 @@ const (
 	Magenta
 	Cyan
 	White
-	Underline
-	Default
+)
+
+// Attr is a set of text attributes. Attributes are independent of a
+// hue's colors and can be combined:
+//
+//	h := hue.Hue{Fg: hue.Red, Attr: hue.Bold | hue.Underline}
+type Attr uint16
+
+// Text attributes
+const (
+	Bold      Attr = 1 << iota // SGR 1
+	Italic                     // SGR 3
+	Underline                  // SGR 4
+	Strike                     // SGR 9, also called crossed-out
+	Dim                        // SGR 2, also called faint
+	Blink                      // SGR 5
+	Reverse                    // SGR 7, swaps the foreground and background
+	Conceal                    // SGR 8, also called hidden
+)
+
+// attrCodes holds the SGR parameter of each attribute in bit order
+var attrCodes = [...]int{1, 3, 4, 9, 2, 5, 7, 8}
+
+// Default selects the terminal's default foreground color, or its
+// default background color when used as a background (SGR 39 and 49).
+const Default = 39
+
+// Bright (high-intensity) foreground color codes
+const (
+	BrightBlack = iota + 90
+	BrightRed
+	BrightGreen
+	BrightBrown // Usually rendered as a bright yellow
+	BrightBlue
+	BrightMagenta
+	BrightCyan
+	BrightWhite
 )
 
 // For iterating through all color codes
@@ -38,8 +77,8 @@ const (
 	// ASCIIFmtReset is a combination of ASCIIFmt and ASCIIReset with a value is sandwiched in between.
 	ASCIIFmtReset = "\033[%d;%dm%v\033[0m"
 
-	// fmt.Sprintf can't accept arguments in the form fmt.Sprintf("%s %s %s %s", "a", "b", []interface{ "c", "d"}...)
-	// see Encode for the workaround that uses this constant
+	// ASCIIFmtFmtReset is like ASCIIFmtReset, except the value placeholder is
+	// escaped so the formatted result can itself be used as a format string.
 	ASCIIFmtFmtReset = "\033[%d;%dm%%v\033[0m"
 )
 
@@ -54,6 +93,15 @@ var StringToHue = map[string]int{
 	"magenta": Magenta,
 	"red":     Red,
 	"white":   White,
+
+	"brightblack":   BrightBlack,
+	"brightblue":    BrightBlue,
+	"brightbrown":   BrightBrown,
+	"brightcyan":    BrightCyan,
+	"brightgreen":   BrightGreen,
+	"brightmagenta": BrightMagenta,
+	"brightred":     BrightRed,
+	"brightwhite":   BrightWhite,
 }
 
 // HueToString is a map of hue color codes to their names
@@ -67,43 +115,379 @@ var HueToString = map[int]string{
 	Magenta: "magenta",
 	Red:     "red",
 	White:   "white",
+
+	BrightBlack:   "brightblack",
+	BrightBlue:    "brightblue",
+	BrightBrown:   "brightbrown",
+	BrightCyan:    "brightcyan",
+	BrightGreen:   "brightgreen",
+	BrightMagenta: "brightmagenta",
+	BrightRed:     "brightred",
+	BrightWhite:   "brightwhite",
 }
 
 // SetHue sets the Writer's hue
 func (w *Writer) SetHue(h *Hue) {
+	w.mu.Lock()
 	w.Hue = h
+	w.mu.Unlock()
 }
 
-// NewWriter returns a new Writer with the hue 'h'
+// NewWriter returns a new Writer with the hue 'h'. If w is an *os.File
+// that is not a terminal, the Writer writes plain text; see ForceColor
+// and SetEnabled.
 func NewWriter(w io.Writer, h *Hue) *Writer {
 	n := new(Writer)
 	n.wrapped = w
+	n.color = detect(w)
 	n.SetHue(h)
 	return n
 }
 
 // Write colorizes and writes the contents of p to the underlying
-// writer object.
-func (w Writer) Write(p []byte) (n int, err error) {
-	return w.wrapped.Write([]byte(w.Sprintf("%s", string(p))))
+// writer object. Each call is written to the underlying writer in a
+// single Write, and concurrent calls don't interleave. As io.Writer
+// requires, n counts the bytes of p written, not the color codes
+// surrounding them; see Written for the total.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(p)
+}
+
+// write colorizes and writes p. The caller holds w.mu.
+func (w *Writer) write(p []byte) (n int, err error) {
+	d := w.color.depth()
+	if w.escapes != EscapeWrap {
+		w.renderEscapes(d, p)
+		nb, err := w.wrapped.Write(w.eout.b)
+		w.written += int64(nb)
+		if err != nil {
+			return w.eout.count(nb), err
+		}
+		return len(p), nil
+	}
+	out, esc := w.render(d, p)
+	nb, err := w.wrapped.Write(out)
+	w.written += int64(nb)
+	if esc != nil {
+		// out is the Writer's buffer, not p; keep it for the next write
+		w.out, w.esc = out[:0], esc[:0]
+	}
+	if err == nil {
+		return len(p), nil
+	}
+
+	// Count the bytes of p that made it, less the color codes among them
+	n = nb
+	for _, e := range esc {
+		if e.off >= nb {
+			break
+		}
+		n -= min(e.n, nb-e.off)
+	}
+	return max(0, min(n, len(p))), err
+}
+
+// escape is a run of n bytes of color codes at offset off in the
+// rendered output
+type escape struct{ off, n int }
+
+// render colorizes p in EscapeWrap mode and returns the result with
+// the positions of the color codes in it. The result is p itself if it
+// has no color codes. The caller holds w.mu.
+func (w *Writer) render(d Depth, p []byte) (out []byte, esc []escape) {
+	out, esc = w.out[:0], w.esc[:0]
+	codes := w.codes(d)
+	link := w.link != "" && d != DepthNone
+	if codes == "" && !link {
+		return p, nil
+	}
+	var open, reset string
+	if codes != "" {
+		open, reset = "\033["+codes+"m", ASCIIReset
+	}
+	add := func(s string) {
+		if s != "" {
+			esc = append(esc, escape{len(out), len(s)})
+			out = append(out, s...)
+		}
+	}
+	if link {
+		add(linkStart(w.link))
+	}
+	if !w.restart {
+		add(open)
+		out = append(out, p...)
+		add(reset)
+	}
+	for w.restart && len(p) > 0 {
+		line := p
+		nl := bytes.IndexByte(p, '\n')
+		if nl >= 0 {
+			line = p[:nl]
+		}
+		if len(line) > 0 {
+			add(open)
+			out = append(out, line...)
+			add(reset)
+		}
+		if nl < 0 {
+			break
+		}
+		out = append(out, '\n')
+		p = p[nl+1:]
+	}
+	if link {
+		add(OSC8End)
+	}
+	return out, esc
+}
+
+// renderEscapes is render for the EscapePass and EscapeStrip modes. It
+// leaves the result in w.eout. The caller holds w.mu.
+func (w *Writer) renderEscapes(d Depth, p []byte) {
+	w.eout.reset()
+	add := w.eout.add
+	pass := w.escapes == EscapePass && d != DepthNone
+	opened := false
+	open := func() {
+		if codes := w.Merge(&w.input).codes(d); codes != "" {
+			add("\033[" + codes + "m")
+			opened = true
+		}
+	}
+	link := w.link != "" && d != DepthNone
+	if link {
+		add(linkStart(w.link))
+	}
+	w.lex.feed(p, func(b []byte, isEsc bool) {
+		if isEsc {
+			if !pass {
+				return
+			}
+			params, ok := sgrParams(b)
+			if !ok {
+				add(string(b))
+				return
+			}
+			w.input.apply(params)
+			if opened {
+				// Select the Writer's hue again in case b reset it
+				add(ASCIIReset)
+				opened = false
+				open()
+			}
+			return
+		}
+		for len(b) > 0 {
+			line := b
+			nl := bytes.IndexByte(b, '\n')
+			if w.restart && nl >= 0 {
+				line = b[:nl]
+			}
+			if !opened && len(line) > 0 {
+				open()
+			}
+			w.eout.copy(p, line)
+			b = b[len(line):]
+			if len(b) > 0 {
+				// A newline in line restart mode
+				if opened {
+					add(ASCIIReset)
+					opened = false
+				}
+				w.eout.copy(p, b[:1])
+				b = b[1:]
+			}
+		}
+	})
+	if opened {
+		add(ASCIIReset)
+	}
+	if link {
+		add(OSC8End)
+	}
+}
+
+// EscapeMode selects what a Writer does with escape sequences that are
+// already in its input
+type EscapeMode int
+
+const (
+	// EscapeWrap colors the input as is, escape sequences included.
+	// An SGR reset in the input turns off the Writer's hue for the
+	// rest of the write.
+	EscapeWrap EscapeMode = iota
+
+	// EscapePass passes escape sequences through. The colors selected
+	// by the input are layered over the Writer's hue, and after a reset
+	// in the input, the Writer's hue is selected again.
+	EscapePass
+
+	// EscapeStrip removes escape sequences from the input before
+	// coloring it
+	EscapeStrip
+)
+
+// SetEscapeMode sets what the Writer does with escape sequences in its
+// input. The default is EscapeWrap. In EscapePass mode, the input's
+// colors are dropped while colorization is off.
+func (w *Writer) SetEscapeMode(m EscapeMode) {
+	w.mu.Lock()
+	w.escapes = m
+	w.mu.Unlock()
+}
+
+// SetLineRestart sets whether the Writer closes its color before each
+// newline and opens it again on the next line. Every line then carries
+// its own color codes, so output stays correct when lines are
+// reordered, truncated or read from the middle of a file.
+func (w *Writer) SetLineRestart(on bool) {
+	w.mu.Lock()
+	w.restart = on
+	w.mu.Unlock()
+}
+
+// SetLink sets the target of an OSC 8 hyperlink that the Writer wraps
+// around the text of each write, as Link does, so terminals that
+// support it display the text as a link to url. An empty url, the
+// default, turns the link off. No link is written while colors are off.
+func (w *Writer) SetLink(url string) {
+	w.mu.Lock()
+	w.link = url
+	w.mu.Unlock()
+}
+
+// ReadFrom colorizes and writes the contents of r to the underlying
+// writer until EOF, reusing one buffer for the reads and one for the
+// output. Each read is colorized as if passed to Write. It implements
+// io.ReaderFrom, so io.Copy uses it.
+func (w *Writer) ReadFrom(r io.Reader) (n int64, err error) {
+	return readFrom(w, r)
+}
+
+// readFrom writes the contents of r to w until EOF, in chunks read
+// into a single buffer
+func readFrom(w io.Writer, r io.Reader) (n int64, err error) {
+	buf := make([]byte, 32*1024)
+	for {
+		nr, er := r.Read(buf)
+		if nr > 0 {
+			nw, ew := w.Write(buf[:nr])
+			n += int64(nw)
+			if ew != nil {
+				return n, ew
+			}
+		}
+		if er == io.EOF {
+			return n, nil
+		}
+		if er != nil {
+			return n, er
+		}
+	}
+}
+
+// Flush writes a reset to the underlying writer, so the terminal is
+// not left colored if the program stops mid-line, and flushes the
+// underlying writer if it has a Flush method, as bufio.Writer does.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return flushReset(w.wrapped, w.color.depth())
+}
+
+// Close flushes the Writer. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// flushReset writes a reset to w unless colors are off, then flushes w
+// if it has a Flush method
+func flushReset(w io.Writer, d Depth) error {
+	if d != DepthNone {
+		if _, err := io.WriteString(w, ASCIIReset); err != nil {
+			return err
+		}
+	}
+	return flushWrapped(w)
+}
+
+// flushWrapped flushes w if it has a Flush method
+func flushWrapped(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Written returns the number of bytes written to the underlying writer,
+// including color codes
+func (w *Writer) Written() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// Writer. When disabled, the Writer writes plain text.
+func (w *Writer) SetEnabled(on bool) {
+	w.mu.Lock()
+	w.color.set(on)
+	w.mu.Unlock()
 }
 
 // WriteString colorizes and writes the string s to the
 // underlying writer object
-func (w Writer) WriteString(s string) (int, error) {
+func (w *Writer) WriteString(s string) (int, error) {
 	return w.Write([]byte(s))
 }
 
-// Writer implements colorization for an underlying io.Writer object
+// Writer implements colorization for an underlying io.Writer object.
+// A Writer is safe for concurrent use.
 type Writer struct {
 	*Hue
+	mu      sync.Mutex
 	wrapped io.Writer
+	color   toggle
+	restart bool
+	link    string // the target of SetLink
+	escapes EscapeMode
+	lex     lexer
+	input   Hue // the style selected by the input in EscapePass mode
+	out     []byte
+	esc     []escape
+	eout    output // the output in the EscapePass and EscapeStrip modes
+	written int64
 }
 
 // String is a string containing ECMA-48 color codes. Its purpose is to
 // remind the user at compile time that it differs from the string builtin.
 type String string
 
+// marshalColors is set when Strings keep their color codes when
+// marshaled
+var marshalColors atomic.Bool
+
+// SetMarshalColors sets whether Strings keep their color codes when
+// marshaled with MarshalText, and so as JSON. By default the codes are
+// stripped, so colored text can be put in JSON APIs and structured
+// logs as plain text.
+func SetMarshalColors(keep bool) {
+	marshalColors.Store(keep)
+}
+
+// MarshalText implements encoding.TextMarshaler. The text is the
+// String without its escape sequences, as Strip returns it, unless
+// SetMarshalColors(true) was called.
+func (hs String) MarshalText() ([]byte, error) {
+	if marshalColors.Load() {
+		return []byte(hs), nil
+	}
+	return []byte(Strip(string(hs))), nil
+}
+
 // New creates a new hue object with foreground and background colors specified.
 func New(fg, bg int) *Hue {
 	h := new(Hue)
@@ -112,170 +496,250 @@ func New(fg, bg int) *Hue {
 	return h
 }
 
+// NewChecked is like New, except it returns an error if either color
+// is not a valid color code. A color of 0 leaves the color unset.
+func NewChecked(fg, bg int) (*Hue, error) {
+	h := New(fg, bg)
+	if h.Fg != 0 && !ValidColor(h.Fg) {
+		return nil, fmt.Errorf("hue: invalid foreground color %d", fg)
+	}
+	if h.Bg != 0 && !ValidColor(h.Bg) {
+		return nil, fmt.Errorf("hue: invalid background color %d", bg)
+	}
+	return h, nil
+}
+
+// SetFg sets the foreground color
 func (h *Hue) SetFg(c int) {
-	h.fg = c
+	h.Fg = normalize(c)
 }
 
+// SetBg sets the background color. The color is one of the
+// foreground color codes; it is converted to a background code
+// when the hue is encoded. ECMA-48 background codes, such as 41
+// for a red background, are converted to the matching foreground
+// code.
 func (h *Hue) SetBg(c int) {
-	h.bg = c + 10
+	h.Bg = normalize(c)
 }
 
-func (h *Hue) Fg() int {
-	return h.fg
+// SetAttr sets the text attributes
+func (h *Hue) SetAttr(a Attr) {
+	h.Attr = a
 }
 
-func (h *Hue) Bg() int {
-	return h.bg
+// WithFg returns a copy of the hue with the foreground color set to c.
+// The receiver is not modified.
+func (h *Hue) WithFg(c int) *Hue {
+	n := *h
+	n.SetFg(c)
+	return &n
 }
 
-// Hue holds the foreground color and background color as integers
-type Hue struct {
-	fg, bg int
+// WithBg returns a copy of the hue with the background color set to c.
+// The receiver is not modified.
+func (h *Hue) WithBg(c int) *Hue {
+	n := *h
+	n.SetBg(c)
+	return &n
 }
 
-// Decode strips all color data from the String object
-// and returns a standard string
-func (hs String) Decode() (s string) {
-	if l := len(hs); l < 12 {
-		panic(fmt.Sprintf("Can't decode hue.String: \"%s\" because it's length is \"%d\" (minimum length is 12)", hs, l))
-	}
-
-	b := []byte(hs)
-	return string(b[8 : len(b)-4])
+// WithAttr returns a copy of the hue with the attributes a added.
+// The receiver is not modified.
+func (h *Hue) WithAttr(a Attr) *Hue {
+	n := *h
+	n.SetAttr(h.Attr | a)
+	return &n
 }
 
-// Encode encapsulates interface a's string representation
-// with the ECMA-40 color codes stored in the hue structure.
-func Encode(h *Hue, a ...interface{}) String {
-	finalFmt := fmt.Sprintf(ASCIIFmtFmtReset, h.Fg(), h.Bg())
-	return String(fmt.Sprintf(finalFmt, a...))
-
-	//return String(fmt.Sprintf(ASCIIFmtReset, h.Fg(), h.Bg(), a))
+// Merge returns a copy of the hue layered with other: colors and
+// underline styles set in other replace the receiver's, those unset in
+// other are inherited
+// from the receiver, and the attributes of both are combined. It is used
+// to layer a highlight over a base style:
+//
+//	base := &hue.Hue{Fg: hue.White, Bg: hue.Blue}
+//	match := base.Merge(&hue.Hue{Fg: hue.Red, Attr: hue.Bold})	// bold red on blue
+func (h *Hue) Merge(other *Hue) *Hue {
+	n := *h
+	if other.Fg != 0 {
+		n.Fg = other.Fg
+	}
+	if other.Bg != 0 {
+		n.Bg = other.Bg
+	}
+	if other.UnderlineStyle != 0 {
+		n.UnderlineStyle = other.UnderlineStyle
+	}
+	if other.UnderlineColor != 0 {
+		n.UnderlineColor = other.UnderlineColor
+	}
+	n.Attr |= other.Attr
+	return &n
 }
 
-// Sprintf behaves like fmt.Sprintf, except it colorizes the output String
-func (h *Hue) Sprintf(format string, a ...interface{}) String {
-	return String(fmt.Sprintf(string(Encode(h, format)), a...))
+// Inherit returns a copy of the hue with its unset colors taken from
+// parent and parent's attributes added. It is the same as parent.Merge(h).
+func (h *Hue) Inherit(parent *Hue) *Hue {
+	return parent.Merge(h)
 }
 
-// Printf behaves like fmt.Printf, except it colorizes the output
-func (h *Hue) Printf(format string, a ...interface{}) {
-	fmt.Printf(string(Encode(h, format)), a...)
+// When returns h if cond is true. Otherwise it returns a hue with
+// no colors set, whose output functions produce plain text. It
+// replaces branches on whether colors are wanted:
+//
+//	h := hue.New(hue.Red, hue.Default).When(*colorFlag)
+//	h.Println("error")
+func (h *Hue) When(cond bool) *Hue {
+	if cond {
+		return h
+	}
+	return new(Hue)
 }
 
-// Print behaves like fmt.Print, except it colorizes the output
-func (h *Hue) Print(a ...interface{}) {
-	fmt.Print(Encode(h, a...))
+// codes returns the hue's ECMA-48 parameters separated by semicolons,
+// with colors degraded to the depth d. Colors that are not set or are
+// invalid are omitted.
+func (h *Hue) codes(d Depth) string {
+	if d == DepthNone {
+		return ""
+	}
+	var p []string
+	for i, code := range attrCodes {
+		if h.Attr&(1<<i) == 0 {
+			continue
+		}
+		if 1<<i == Underline && h.UnderlineStyle != 0 {
+			continue
+		}
+		p = append(p, strconv.Itoa(code))
+	}
+	if h.UnderlineStyle != 0 {
+		p = append(p, "4:"+strconv.Itoa(int(h.UnderlineStyle)))
+	}
+	if c := Degrade(normalize(h.Fg), d); ValidColor(c) {
+		p = append(p, colorCode(c, false))
+	}
+	if c := Degrade(normalize(h.Bg), d); ValidColor(c) {
+		p = append(p, colorCode(c, true))
+	}
+	if c := underlineCode(normalize(h.UnderlineColor), d); c != "" {
+		p = append(p, c)
+	}
+	return strings.Join(p, ";")
 }
 
-// Println behaves like fmt.Println, except it colorizes the output
-func (h *Hue) Println(a ...interface{}) {
-	fmt.Println(Encode(h, a...))
+// sgr returns the escape sequence that selects the hue at the depth d
+func (h *Hue) sgr(d Depth) string {
+	return "\033[" + h.codes(d) + "m"
 }
 
-// RegexpWriter implements colorization for a io.Writer object by processing
-// a set of rules. Rules are hue objects assocated with regular expressions.
-type RegexpWriter struct {
-	rules   []rule
-	wrapped io.Writer
+// Hue holds the foreground color and background color as integers,
+// and a set of text attributes. Both color fields take the foreground
+// color codes, so a hue can be created with a composite literal:
+//
+//	h := hue.Hue{Fg: hue.Red, Bg: hue.White}
+//
+// UnderlineStyle and UnderlineColor select the extended underlines
+// supported by some terminals, such as the curly red underline
+// editors use for diagnostics. Terminals without support usually fall
+// back to a plain underline.
+type Hue struct {
+	Fg, Bg         int
+	Attr           Attr
+	UnderlineStyle UnderlineStyle
+	UnderlineColor int
 }
 
-type rule struct {
-	*Hue
-	*regexp.Regexp
-}
+// UnderlineStyle is the shape of an extended underline (SGR 4:n)
+type UnderlineStyle uint8
 
-// NewRegexpWriter returns a new RegexpWriter
-func NewRegexpWriter(w io.Writer) *RegexpWriter {
-	n := new(RegexpWriter)
-	n.wrapped = w
-	return n
-}
+// Underline styles. The zero value selects no extended underline.
+const (
+	UnderlineSingle UnderlineStyle = iota + 1
+	UnderlineDouble
+	UnderlineCurly
+	UnderlineDotted
+	UnderlineDashed
+)
 
-// AddRuleStringPOSIX binds a hue to the POSIX regexp in the string 's'.
-// Similar to AddRule, except the caller passes in an uncompiled POSIX regexp.
-func (w *RegexpWriter) AddRuleStringPOSIX(h *Hue, s string) {
-	re := regexp.MustCompilePOSIX(s)
-	w.AddRule(h, re)
+// Decode strips all color data from the String object
+// and returns a standard string. Every escape sequence is removed,
+// wherever it is, so a String need not come from Encode. A String
+// without color data is returned unchanged. If the String ends in the
+// middle of an escape sequence, Decode returns the text before it and
+// an error.
+func (hs String) Decode() (string, error) {
+	if strings.IndexByte(string(hs), '\033') < 0 {
+		return string(hs), nil
+	}
+	text, complete := strip([]byte(hs))
+	if !complete {
+		return string(text), fmt.Errorf("hue: can't decode %q: unterminated escape sequence", string(hs))
+	}
+	return string(text), nil
 }
 
-// AddRuleString binds a hue to the regexp in the string 's'.
-// Similar to AddRule, except the caller passes in an uncompiled regexp.
-func (w *RegexpWriter) AddRuleString(h *Hue, s string) {
-	re := regexp.MustCompile(s)
-	w.AddRule(h, re)
+// Encode encapsulates interface a's string representation
+// with the ECMA-40 color codes stored in the hue structure.
+// Colors are degraded to the program-wide depth. If the hue has no
+// colors set or colorization is disabled, the string is not colorized.
+func Encode(h *Hue, a ...interface{}) String {
+	return h.encode(inherit.depth(), fmt.Sprint(a...))
 }
 
-// AddRule binds a hue to a regular expression.
-func (w *RegexpWriter) AddRule(h *Hue, re *regexp.Regexp) {
-	//w.rules.PushBack( rule{h, re} )
-	w.rules = append(w.rules, rule{h, re})
+// encode wraps s in the hue's color codes for the depth d
+func (h *Hue) encode(d Depth, s string) String {
+	codes := h.codes(d)
+	if codes == "" {
+		return String(s)
+	}
+	return String("\033[" + codes + "m" + s + ASCIIReset)
 }
 
-// FlushRules deletes all rules added with AddRule from Writer
-func (w *RegexpWriter) FlushRules() {
-	w.rules = nil
+// Sprintf behaves like fmt.Sprintf, except it colorizes the output String
+func (h *Hue) Sprintf(format string, a ...interface{}) String {
+	return String(fmt.Sprintf(string(Encode(h, format)), a...))
 }
 
-// PrintRules prints out the rules
-func (w *RegexpWriter) PrintRules() {
-	for _, v := range w.rules {
-		fmt.Println(v)
-	}
+// Sprint behaves like fmt.Sprint, except it colorizes the output String
+func (h *Hue) Sprint(a ...interface{}) String {
+	return Encode(h, a...)
 }
 
-// WriteString is similar to Write, except it writes a string to the underlying
-// buffer instead of a byte slice.
-func (w RegexpWriter) WriteString(s string) (n int, err error) {
-	return w.Write([]byte(s))
+// Sprintln behaves like fmt.Sprintln, except it colorizes the output String.
+// The trailing newline is placed after the color codes.
+func (h *Hue) Sprintln(a ...interface{}) String {
+	s := fmt.Sprintln(a...)
+	return Encode(h, s[:len(s)-1]) + "\n"
 }
 
-// Write writes the contents of p into the buffer after processesing the regexp
-// rules added to Writer with AddRule. Write colorizes the contents as it writes
-// to the underlying writer object.
-func (w RegexpWriter) Write(p []byte) (n int, err error) {
-	huemap := make([]byte, len(p))
-	rulemap := make([]*Hue, len(w.rules)+1)
-	rulemap[0] = &Hue{}
-
-	for i := 1; i < len(rulemap); i++ {
-		r := w.rules[i-1]
-		x := r.FindAllIndex(p, -1)
-
-		rulemap[i] = r.Hue
-		for _, w := range x {
-			for j := w[0]; j < w[1]; j++ {
-				huemap[j] = byte(i)
-			}
-		}
-	}
+// Printf behaves like fmt.Printf, except it colorizes the output
+func (h *Hue) Printf(format string, a ...interface{}) {
+	fmt.Printf(string(Encode(h, format)), a...)
+}
 
-	var hue byte
-	for i := range p {
-		if huemap[i] != hue {
-			hue = huemap[i]
-			th := rulemap[hue]
+// Print behaves like fmt.Print, except it colorizes the output
+func (h *Hue) Print(a ...interface{}) {
+	fmt.Print(h.Sprint(a...))
+}
 
-			nb, err := fmt.Fprintf(w.wrapped, ASCIIFmt, th.Fg(), th.Bg())
-			if err != nil {
-				return n, err
-			}
-			n += nb
-		}
+// Println behaves like fmt.Println, except it colorizes the output
+func (h *Hue) Println(a ...interface{}) {
+	fmt.Print(h.Sprintln(a...))
+}
 
-		nb, err := fmt.Fprintf(w.wrapped, "%c", p[i])
-		if err != nil {
-			return n, err
-		}
-		n += nb
-	}
+// Fprintf behaves like fmt.Fprintf, except it colorizes the output
+func (h *Hue) Fprintf(w io.Writer, format string, a ...interface{}) (n int, err error) {
+	return io.WriteString(w, string(h.Sprintf(format, a...)))
+}
 
-	for i := range p {
-		if huemap[i] != 0 {
-			fmt.Print(ASCIIReset)
-			break
-		}
-	}
+// Fprint behaves like fmt.Fprint, except it colorizes the output
+func (h *Hue) Fprint(w io.Writer, a ...interface{}) (n int, err error) {
+	return io.WriteString(w, string(h.Sprint(a...)))
+}
 
-	return n, err
+// Fprintln behaves like fmt.Fprintln, except it colorizes the output
+func (h *Hue) Fprintln(w io.Writer, a ...interface{}) (n int, err error) {
+	return io.WriteString(w, string(h.Sprintln(a...)))
 }
@@ -1,7 +1,10 @@
 package hue
 
 import (
-	"fmt"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"testing"
 )
 
@@ -13,8 +16,10 @@ func TestEncodeDecode(t *testing.T) {
 		"Test",               // Less than 10 characters, but not empty
 		"",                   // Empty
 		"\300\r\n\t\x0d\x0a", // Non-printables
-		"\033[00;00m\033[0m", // Characters that mimic the ECMA encoding
 	}
+	// Escape sequences in the text are decoded too
+	mimic := "\033[00;00m\033[0m"
+	testInputs = append(testInputs, mimic)
 
 	/*
 	 * Test the input strings with every possible foreground / background color
@@ -27,11 +32,256 @@ func TestEncodeDecode(t *testing.T) {
 				h.SetFg(i)
 				h.SetBg(j)
 				hs := Encode(h, u)
-				if u != hs.Decode() {
-					t.Log(fmt.Sprintf("%s != %s", u, hs.Decode()))
-					t.Fail()
+				want := u
+				if u == mimic {
+					want = ""
+				}
+				if s, err := hs.Decode(); want != s || err != nil {
+					t.Errorf("%q != %q (%v)", want, s, err)
 				}
 			}
 		}
 	}
 }
+
+func TestCompositeLiteral(t *testing.T) {
+	want := Encode(New(Red, White), "x")
+	have := Encode(&Hue{Fg: Red, Bg: White}, "x")
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if want != "\033[31;47mx\033[0m" {
+		t.Errorf("unexpected encoding %q", want)
+	}
+}
+
+func TestSprintfVariadic(t *testing.T) {
+	h := New(Green, Default)
+	have := h.Sprintf("%s=%d", "key", 42)
+	if want := Encode(h, "key=42"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestSprintSprintln(t *testing.T) {
+	h := New(Red, Default)
+	if have, want := h.Sprint("a", 1, 2, "b"), Encode(h, "a1 2b"); have != want {
+		t.Errorf("Sprint: have %q, want %q", have, want)
+	}
+	if have, want := h.Sprintln("a", 1, 2, "b"), Encode(h, "a 1 2 b")+"\n"; have != want {
+		t.Errorf("Sprintln: have %q, want %q", have, want)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	h := New(Cyan, Black)
+	var b bytes.Buffer
+	h.Fprint(&b, "a", "b")
+	h.Fprintf(&b, "%d", 1)
+	h.Fprintln(&b, "c")
+	want := string(h.Sprint("ab") + h.Sprint("1") + h.Sprintln("c"))
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	h := New(Red, Default)
+	err := h.Errorf("bad input: %w", io.EOF)
+	if have, want := err.Error(), string(h.Sprint("bad input: EOF")); have != want {
+		t.Errorf("Error: have %q, want %q", have, want)
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("errors.As: %T is not an *Error", err)
+	}
+	if have := e.Plain(); have != "bad input: EOF" {
+		t.Errorf("Plain: have %q", have)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("errors.Is: wrapped error not found")
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder().Fg(Red).Bg(Default)
+	h := b.Hue()
+	if *h != *New(Red, Default) {
+		t.Errorf("have %+v, want %+v", *h, *New(Red, Default))
+	}
+	b.Fg(Blue)
+	if h.Fg != Red {
+		t.Errorf("builder modified a returned hue")
+	}
+}
+
+func TestWithFgBg(t *testing.T) {
+	h := New(Red, Black)
+	fg, bg := h.WithFg(Green), h.WithBg(White)
+	if *h != *New(Red, Black) {
+		t.Errorf("receiver modified: %+v", *h)
+	}
+	if *fg != *New(Green, Black) || *bg != *New(Red, White) {
+		t.Errorf("have %+v and %+v", *fg, *bg)
+	}
+}
+
+func TestEncodeUnset(t *testing.T) {
+	if have, want := Encode(&Hue{Fg: Red}, "x"), String("\033[31mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, _ := Encode(&Hue{Bg: Red}, "x").Decode(); have != "x" {
+		t.Errorf("Decode: have %q", have)
+	}
+}
+
+func TestWhen(t *testing.T) {
+	h := New(Red, Default)
+	if h.When(true) != h {
+		t.Errorf("When(true) did not return the receiver")
+	}
+	off := h.When(false)
+	if have := off.Sprintf("%d items", 3); have != "3 items" {
+		t.Errorf("When(false).Sprintf: have %q", have)
+	}
+	if have, _ := off.Sprint("x").Decode(); have != "x" {
+		t.Errorf("Decode: have %q", have)
+	}
+}
+
+func TestDecodeForeign(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		err      bool
+	}{
+		{"\033[", "", true},
+		{"a\033[1;38;5;208mb\033[0mc\033[4:3md", "abcd", false},
+		{"\033]8;;http://x\033\\link\033]8;;\033\\", "link", false},
+		{"x\033[31", "x", true},
+	} {
+		have, err := String(tc.in).Decode()
+		if have != tc.want || (err != nil) != tc.err {
+			t.Errorf("%q: have %q, %v; want %q", tc.in, have, err, tc.want)
+		}
+	}
+}
+
+func TestMarshalString(t *testing.T) {
+	v := struct{ Msg String }{New(Red, 0).Sprint("failed")}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `{"Msg":"failed"}` {
+		t.Errorf("stripped: have %s, %v", b, err)
+	}
+	SetMarshalColors(true)
+	defer SetMarshalColors(false)
+	b, _ = json.Marshal(v)
+	if want := `{"Msg":"\u001b[31mfailed\u001b[0m"}`; string(b) != want {
+		t.Errorf("kept: have %s, want %s", b, want)
+	}
+}
+
+func TestBright(t *testing.T) {
+	hs := Encode(New(BrightCyan, BrightBlack), "x")
+	if want := String("\033[96;100mx\033[0m"); hs != want {
+		t.Errorf("have %q, want %q", hs, want)
+	}
+	if s, err := hs.Decode(); s != "x" || err != nil {
+		t.Errorf("Decode: have %q, %v", s, err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if have, want := Encode(New(Default, Default), "x"), String("\033[39;49mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := Encode(New(Red, Default), "x"), String("\033[31;49mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestAttr(t *testing.T) {
+	h := &Hue{Fg: Red, Attr: Bold | Underline}
+	if have, want := Encode(h, "x"), String("\033[1;4;31mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := Encode(&Hue{Attr: Italic | Strike}, "x"), String("\033[3;9mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := Encode(&Hue{Attr: Dim | Blink | Reverse | Conceal}, "x"), String("\033[2;5;7;8mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if b := NewBuilder().Reverse().Dim().Hue(); b.Attr != Reverse|Dim {
+		t.Errorf("Builder: have %+v", *b)
+	}
+	if b := NewBuilder().Fg(Red).Bold().Underline().Hue(); *b != *h {
+		t.Errorf("Builder: have %+v, want %+v", *b, *h)
+	}
+	if w := New(Red, 0).WithAttr(Bold).WithAttr(Underline); *w != *h {
+		t.Errorf("WithAttr: have %+v, want %+v", *w, *h)
+	}
+}
+
+func TestRegexpWriterAttr(t *testing.T) {
+	var b bytes.Buffer
+	re := NewRegexpWriter(&b)
+	re.AddRuleString(&Hue{Attr: Bold}, "a")
+	re.AddRuleString(&Hue{Fg: Red}, "b")
+	re.WriteString("ab")
+	if have, want := b.String(), "\033[0;1ma\033[0;31mb\033[0m"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &Hue{Fg: White, Bg: Blue, Attr: Italic}
+	over := &Hue{Fg: Red, Attr: Bold}
+	want := Hue{Fg: Red, Bg: Blue, Attr: Italic | Bold}
+	if have := base.Merge(over); *have != want {
+		t.Errorf("Merge: have %+v, want %+v", *have, want)
+	}
+	if have := over.Inherit(base); *have != want {
+		t.Errorf("Inherit: have %+v, want %+v", *have, want)
+	}
+	if base.Fg != White || over.Bg != 0 {
+		t.Errorf("Merge modified its operands")
+	}
+}
+
+func TestUnderlineStyle(t *testing.T) {
+	h := &Hue{Attr: Underline, UnderlineStyle: UnderlineCurly, UnderlineColor: Red}
+	if have, want := Encode(h, "x"), String("\033[4:3;58;5;1mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	h = &Hue{Fg: Red, UnderlineStyle: UnderlineDouble, UnderlineColor: RGB(1, 2, 3)}
+	if have, want := Encode(h, "x"), String("\033[4:2;31;58;2;1;2;3mx\033[0m"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	defer SetDepth(DepthTrue)
+	SetDepth(Depth16)
+	if have, want := Encode(h, "x"), String("\033[4:2;31mx\033[0m"); have != want {
+		t.Errorf("Depth16: have %q, want %q", have, want)
+	}
+	Disable()
+	defer Enable()
+	if have := Encode(&Hue{Attr: Bold}, "x"); have != "x" {
+		t.Errorf("disabled: have %q", have)
+	}
+}
+
+func TestRegexpWriterReset(t *testing.T) {
+	var b bytes.Buffer
+	re := NewRegexpWriter(&b)
+	re.AddRuleString(New(Red, 0), "a")
+	for _, tc := range []struct{ in, want string }{
+		{"xa", "x\033[0;31ma\033[0m"},
+		{"ax", "\033[0;31ma\033[0mx"},
+		{"x", "x"},
+	} {
+		b.Reset()
+		re.WriteString(tc.in)
+		if have := b.String(); have != tc.want {
+			t.Errorf("%q: have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package hue
+
+import "io"
+
+// IndentWriter indents every line written through it, so the colored
+// output of a sub-task can be nested under its parent:
+//
+//	w := hue.NewIndentWriter(os.Stdout, strings.Repeat(" ", 4))
+//
+// The indentation is written outside the colors selected by the input,
+// and escape sequences at the start of a line stay in the output
+// without shifting the text. Empty lines are not indented. An
+// IndentWriter is safe for concurrent use.
+type IndentWriter struct {
+	prefixer
+}
+
+// NewIndentWriter returns an IndentWriter that writes to w, starting
+// each line with indent. The indent may contain color codes of its own.
+func NewIndentWriter(w io.Writer, indent string) *IndentWriter {
+	n := new(IndentWriter)
+	n.init(w, func(Depth) string { return indent })
+	n.blank = false
+	return n
+}
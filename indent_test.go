@@ -0,0 +1,19 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIndentWriter(t *testing.T) {
+	var b bytes.Buffer
+	outer := NewIndentWriter(&b, "  ")
+	inner := NewIndentWriter(outer, "  ")
+	inner.WriteString("a\n\n")
+	inner.WriteString(string(New(Red, 0).Sprint("b\nc")) + "\n")
+	want := "    a\n\n" + "\033[31m" + ASCIIReset + "    \033[31mb\n" +
+		ASCIIReset + "    \033[31mc\033[0m\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+}
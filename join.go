@@ -0,0 +1,56 @@
+package hue
+
+import "strings"
+
+// Join concatenates the parts, putting sep between them. Each part
+// keeps its own colors without being encoded again: where a part
+// leaves colors selected at its end, as a String cut by hand might, a
+// reset is added after it so they don't spill into what follows.
+func Join(sep String, parts ...String) String {
+	var b strings.Builder
+	for i, s := range parts {
+		if i > 0 {
+			b.WriteString(string(sep))
+			closeStyle(&b, sep)
+		}
+		b.WriteString(string(s))
+		closeStyle(&b, s)
+	}
+	return String(b.String())
+}
+
+// Append returns s followed by the text of a, formatted as by
+// fmt.Sprint and encoded with the hue h as Encode would. Unlike
+// Encode(h, s, a), it leaves the colors of s as they are instead of
+// wrapping them in those of h. A reset is added after s if it leaves
+// colors selected.
+func Append(s String, h *Hue, a ...interface{}) String {
+	var b strings.Builder
+	b.WriteString(string(s))
+	closeStyle(&b, s)
+	b.WriteString(string(Encode(h, a...)))
+	return String(b.String())
+}
+
+// closeStyle writes a reset to b if s leaves colors selected at its
+// end
+func closeStyle(b *strings.Builder, s String) {
+	if strings.IndexByte(string(s), '\033') < 0 {
+		return
+	}
+	var (
+		l     lexer
+		style Hue
+	)
+	l.feed([]byte(s), func(t []byte, esc bool) {
+		if !esc {
+			return
+		}
+		if params, ok := sgrParams(t); ok {
+			style.apply(params)
+		}
+	})
+	if style != (Hue{}) {
+		b.WriteString(ASCIIReset)
+	}
+}
@@ -0,0 +1,28 @@
+package hue
+
+import "testing"
+
+func TestJoin(t *testing.T) {
+	red, bold := New(Red, 0).Sprint("red"), String("\033[1mopen")
+	for _, tc := range []struct{ have, want String }{
+		{Join(", "), ""},
+		{Join(", ", "a", red, "b"), "a, " + red + ", b"},
+		{Join(" | ", bold, "x"), bold + ASCIIReset + " | x"},
+		{Join("\033[2m-", "a", "b"), "a\033[2m-" + ASCIIReset + "b"},
+	} {
+		if tc.have != tc.want {
+			t.Errorf("have %q, want %q", tc.have, tc.want)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	s := New(Red, 0).Sprint("red")
+	have := Append(Append(s, New(Blue, 0), " blue"), &Hue{}, " plain")
+	if want := s + "\033[34m blue" + ASCIIReset + " plain"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if have, want := Append("\033[1mbold", New(Blue, 0), "x"), String("\033[1mbold"+ASCIIReset+"\033[34mx"+ASCIIReset); have != want {
+		t.Errorf("open: have %q, want %q", have, want)
+	}
+}
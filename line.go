@@ -0,0 +1,128 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// LineWriter colors whole lines in the hue chosen for each line by a
+// function. It is simpler and cheaper than a RegexpWriter when the
+// color depends on the line as a whole:
+//
+//	w := hue.NewLineWriter(os.Stdout, func(line []byte) *hue.Hue {
+//		switch {
+//		case bytes.HasPrefix(line, []byte("ERROR")):
+//			return hue.New(hue.Red, 0)
+//		case bytes.HasPrefix(line, []byte("WARN")):
+//			return hue.New(hue.Brown, 0)
+//		}
+//		return nil
+//	})
+//
+// Lines are written once they are complete; Flush writes the last line
+// if it has no newline. A LineWriter is safe for concurrent use.
+type LineWriter struct {
+	mu      sync.Mutex
+	wrapped io.Writer
+	color   toggle
+	fn      func(line []byte) *Hue
+	format  func(d Depth, data, line []byte) // used instead of fn if set
+	partial []byte                           // the last line, until its newline is written
+	out     output
+}
+
+// NewLineWriter returns a LineWriter that writes to w. The function fn
+// is called with each line, without its newline, and returns its hue,
+// or nil to write it uncolored.
+func NewLineWriter(w io.Writer, fn func(line []byte) *Hue) *LineWriter {
+	return &LineWriter{wrapped: w, color: detect(w), fn: fn}
+}
+
+// Write writes the complete lines in p, and any partial line before
+// them, to the underlying writer. On success, n is len(p).
+func (w *LineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	carried := len(w.partial)
+	data := append(w.partial, p...)
+	d := w.color.depth()
+	w.out.reset()
+	rest := data
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(d, data, rest[:i])
+		w.out.copy(data, rest[i:i+1])
+		rest = rest[i+1:]
+	}
+	w.partial = append(w.partial[:0], rest...)
+	if len(w.out.b) == 0 {
+		return len(p), nil
+	}
+	nb, err := w.wrapped.Write(w.out.b)
+	if err != nil {
+		return max(0, w.out.count(nb)-carried), err
+	}
+	return len(p), nil
+}
+
+// line colors and adds a line, which is a subslice of data
+func (w *LineWriter) line(d Depth, data, line []byte) {
+	if w.format != nil {
+		w.format(d, data, line)
+		return
+	}
+	w.paint(d, data, line, w.fn(line))
+}
+
+// paint adds b, a subslice of data, in the hue h
+func (w *LineWriter) paint(d Depth, data, b []byte, h *Hue) {
+	codes := ""
+	if h != nil {
+		codes = h.codes(d)
+	}
+	if codes == "" || len(b) == 0 {
+		w.out.copy(data, b)
+		return
+	}
+	w.out.add("\033[" + codes + "m")
+	w.out.copy(data, b)
+	w.out.add(ASCIIReset)
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *LineWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush writes the last line if it has no newline, and flushes the
+// underlying writer if it has a Flush method
+func (w *LineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.out.reset()
+		w.line(w.color.depth(), w.partial, w.partial)
+		w.partial = w.partial[:0]
+		if _, err := w.wrapped.Write(w.out.b); err != nil {
+			return err
+		}
+	}
+	return flushWrapped(w.wrapped)
+}
+
+// Close flushes the LineWriter. It does not close the underlying writer.
+func (w *LineWriter) Close() error {
+	return w.Flush()
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// LineWriter
+func (w *LineWriter) SetEnabled(on bool) {
+	w.mu.Lock()
+	w.color.set(on)
+	w.mu.Unlock()
+}
@@ -0,0 +1,50 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	red, brown := New(Red, 0), New(Brown, 0)
+	var b bytes.Buffer
+	w := NewLineWriter(&b, func(line []byte) *Hue {
+		switch {
+		case bytes.HasPrefix(line, []byte("ERROR")):
+			return red
+		case bytes.HasPrefix(line, []byte("WARN")):
+			return brown
+		}
+		return nil
+	})
+	w.WriteString("ok\nERR")
+	if have := b.String(); have != "ok\n" {
+		t.Errorf("partial line: have %q", have)
+	}
+	w.WriteString("OR: x\n\nWARN")
+	w.Flush()
+	want := "ok\n" + string(Encode(red, "ERROR: x")) + "\n\n" + string(Encode(brown, "WARN"))
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestLineWriterCount(t *testing.T) {
+	red := New(Red, 0)
+	fn := func(line []byte) *Hue {
+		if string(line) == "bb" {
+			return red
+		}
+		return nil
+	}
+	// The output is "aaaa\n\033[31mbb\033[0m\ncc\n"
+	for _, tc := range []struct{ accept, want int }{
+		{3, 3}, {6, 5}, {12, 7}, {16, 7}, {18, 9},
+	} {
+		sw := &shortWriter{n: tc.accept}
+		n, err := NewLineWriter(sw, fn).WriteString("aaaa\nbb\ncc\n")
+		if n != tc.want || err == nil {
+			t.Errorf("accept %d: have %d, %v; want %d", tc.accept, n, err, tc.want)
+		}
+	}
+}
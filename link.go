@@ -0,0 +1,83 @@
+package hue
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	// OSC8Fmt is a format specifier for the start of an OSC 8 hyperlink
+	OSC8Fmt = "\033]8;;%s\033\\"
+	// OSC8End ends an OSC 8 hyperlink
+	OSC8End = "\033]8;;\033\\"
+)
+
+// Link returns text wrapped in an OSC 8 hyperlink to url. Terminals
+// that support OSC 8 display the text as a clickable link; others
+// display the text alone. While colorization is disabled, or the depth
+// is DepthNone, Link returns the text unchanged.
+func Link(url, text string) String {
+	if inherit.depth() == DepthNone {
+		return String(text)
+	}
+	return String(linkStart(url) + text + OSC8End)
+}
+
+// linkStart returns the sequence that starts a hyperlink to url
+func linkStart(url string) string {
+	return fmt.Sprintf(OSC8Fmt, url)
+}
+
+// linkRule turns the matches of a regexp into hyperlinks
+type linkRule struct {
+	*regexp.Regexp
+	url string
+}
+
+// linkSpan is the hyperlink for p[start:end]
+type linkSpan struct {
+	start, end int
+	url        string
+}
+
+// AddLinkRule makes the text matched by re a hyperlink. The link's
+// target is url with $1-style references to the match's submatches
+// expanded as by regexp.Regexp.Expand. For example, to link Go compiler
+// output to the files it refers to:
+//
+//	w.AddLinkRule(regexp.MustCompile(`([\w./-]+\.go):(\d+)`), "file://$1")
+//
+// Link rules are independent of color rules, so linked text keeps its
+// colors. Where the matches of link rules overlap, the earlier rule wins.
+func (w *RegexpWriter) AddLinkRule(re *regexp.Regexp, url string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.links = append(w.links, linkRule{re, url})
+}
+
+// linkSpans returns the hyperlinks in p sorted by position
+func (w *RegexpWriter) linkSpans(p []byte) []linkSpan {
+	var spans []linkSpan
+	taken := make([]bool, len(p))
+	for _, r := range w.links {
+	Matches:
+		for _, m := range r.FindAllSubmatchIndex(p, -1) {
+			if m[0] == m[1] {
+				continue
+			}
+			for i := m[0]; i < m[1]; i++ {
+				if taken[i] {
+					continue Matches
+				}
+			}
+			for i := m[0]; i < m[1]; i++ {
+				taken[i] = true
+			}
+			url := r.Expand(nil, []byte(r.url), p, m)
+			spans = append(spans, linkSpan{m[0], m[1], string(url)})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
@@ -0,0 +1,50 @@
+package hue
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	if have, want := Link("https://example.com", "site"), String("\033]8;;https://example.com\033\\site\033]8;;\033\\"); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	Disable()
+	defer Enable()
+	if have := Link("https://example.com", "site"); have != "site" {
+		t.Errorf("disabled: have %q", have)
+	}
+	Enable()
+	SetDepth(DepthNone)
+	defer SetDepth(DepthTrue)
+	if have := Link("https://example.com", "site"); have != "site" {
+		t.Errorf("DepthNone: have %q", have)
+	}
+}
+
+func TestWriterLink(t *testing.T) {
+	for _, mode := range []EscapeMode{EscapeWrap, EscapeStrip} {
+		var b bytes.Buffer
+		w := NewWriter(&b, New(Red, 0))
+		w.SetEscapeMode(mode)
+		w.SetLink("file:///a.go")
+		w.WriteString("a.go")
+		want := "\033]8;;file:///a.go\033\\\033[31ma.go" + ASCIIReset + OSC8End
+		if have := b.String(); have != want {
+			t.Errorf("mode %d: have %q, want %q", mode, have, want)
+		}
+	}
+}
+
+func TestLinkRule(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddLinkRule(regexp.MustCompile(`(\w+\.go):(\d+)`), "file:///src/$1#L$2")
+	w.WriteString("a.go:1 and b.go:22")
+
+	want := string(Link("file:///src/a.go#L1", "a.go:1")) + " and " + string(Link("file:///src/b.go#L22", "b.go:22"))
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,155 @@
+package hue
+
+import "regexp"
+
+// AddLiteralRule binds a hue to a fixed string, for keywords such as
+// "ERROR" or "WARN". Literal rules don't use regular expressions: all
+// of them are matched in a single pass over the text, however many
+// there are, so a set of keywords is much cheaper to find than with a
+// rule per keyword. Matches are found as regexp.QuoteMeta(s) would
+// find them, and an empty s matches nothing. The rule has priority 0.
+func (w *RegexpWriter) AddLiteralRule(h *Hue, s string) RuleID {
+	return w.add(rule{Hue: h, lit: s})
+}
+
+// pattern returns the source of the regular expression of r, or one
+// matching its literal
+func (r *rule) pattern() string {
+	if r.Regexp == nil {
+		return regexp.QuoteMeta(r.lit)
+	}
+	return r.String()
+}
+
+// literals finds the matches of the literal rules of a RegexpWriter
+// in one pass, with an Aho–Corasick automaton. The automaton is a DFA
+// over classes of bytes: the bytes that appear in no literal share
+// class 0.
+type literals struct {
+	ids   []RuleID // the literal rules the automaton was built for
+	lens  []int    // the length of each literal
+	class [256]uint16
+	k     int     // the number of classes
+	delta []int32 // the next state, at state*k + class
+	out   [][]int // the literals ending at each state
+
+	found [][][2]int // the matches of each literal, for the last scan
+}
+
+// literals returns the automaton for the literal rules of w, in the
+// order they were added, building it if the rules have changed. It
+// returns nil if there are no literal rules.
+func (w *RegexpWriter) literals() *literals {
+	if a := w.lits; a != nil && a.current(w.rules) {
+		return a
+	}
+	var ids []RuleID
+	var pats []string
+	for _, r := range w.rules {
+		if r.Regexp == nil {
+			ids = append(ids, r.id)
+			pats = append(pats, r.lit)
+		}
+	}
+	w.lits = nil
+	if len(ids) > 0 {
+		w.lits = newLiterals(ids, pats)
+	}
+	return w.lits
+}
+
+// current reports whether a was built for the literal rules in rules
+func (a *literals) current(rules []rule) bool {
+	j := 0
+	for _, r := range rules {
+		if r.Regexp == nil {
+			if j == len(a.ids) || a.ids[j] != r.id {
+				return false
+			}
+			j++
+		}
+	}
+	return j == len(a.ids)
+}
+
+// newLiterals builds the automaton matching pats
+func newLiterals(ids []RuleID, pats []string) *literals {
+	a := &literals{ids: ids, found: make([][][2]int, len(pats))}
+	for _, p := range pats {
+		a.lens = append(a.lens, len(p))
+		for i := 0; i < len(p); i++ {
+			if a.class[p[i]] == 0 {
+				a.k++
+				a.class[p[i]] = uint16(a.k)
+			}
+		}
+	}
+	a.k++
+
+	// Build the trie; -1 is a missing edge
+	node := func() int32 {
+		for c := 0; c < a.k; c++ {
+			a.delta = append(a.delta, -1)
+		}
+		a.out = append(a.out, nil)
+		return int32(len(a.out) - 1)
+	}
+	node()
+	for j, p := range pats {
+		if p == "" {
+			continue
+		}
+		s := int32(0)
+		for i := 0; i < len(p); i++ {
+			e := int(s)*a.k + int(a.class[p[i]])
+			if a.delta[e] < 0 {
+				n := node()
+				a.delta[e] = n
+			}
+			s = a.delta[e]
+		}
+		a.out[s] = append(a.out[s], j)
+	}
+
+	// Turn it into a DFA, breadth first, so that the failure state of
+	// each state is complete before the state is
+	fail := make([]int32, len(a.out))
+	queue := []int32{0}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for c := 0; c < a.k; c++ {
+			e := int(u)*a.k + c
+			next := int32(0)
+			if u != 0 {
+				next = a.delta[int(fail[u])*a.k+c]
+			}
+			if v := a.delta[e]; v >= 0 {
+				fail[v] = next
+				a.out[v] = append(a.out[v], a.out[next]...)
+				queue = append(queue, v)
+			} else {
+				a.delta[e] = next
+			}
+		}
+	}
+	return a
+}
+
+// scan finds the matches of every literal in p. The matches of each
+// literal are leftmost and don't overlap, as with FindAllIndex.
+func (a *literals) scan(p []byte) {
+	for j := range a.found {
+		a.found[j] = a.found[j][:0]
+	}
+	s := int32(0)
+	for i, c := range p {
+		s = a.delta[int(s)*a.k+int(a.class[c])]
+		for _, j := range a.out[s] {
+			start := i + 1 - a.lens[j]
+			if f := a.found[j]; len(f) == 0 || f[len(f)-1][1] <= start {
+				a.found[j] = append(f, [2]int{start, i + 1})
+			}
+		}
+	}
+}
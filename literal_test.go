@@ -0,0 +1,63 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestRegexpWriterLiteral(t *testing.T) {
+	words := []string{"he", "she", "hers", "his", "aaa", "a", ""}
+	hues := []*Hue{New(Red, 0), New(Green, 0), New(Blue, 0), New(Cyan, 0),
+		New(Brown, 0), New(Magenta, 0), New(White, 0)}
+	const text = "ushers said his shell is hers; aaaaa\nshe\n"
+
+	var lit, re bytes.Buffer
+	lw, rw := NewRegexpWriter(&lit), NewRegexpWriter(&re)
+	for i, s := range words {
+		lw.AddLiteralRule(hues[i], s)
+		if s != "" {
+			rw.AddRule(hues[i], regexp.MustCompile(regexp.QuoteMeta(s)))
+		}
+	}
+	lw.WriteString(text)
+	rw.WriteString(text)
+	if lit.String() != re.String() {
+		t.Errorf("literal:\n%q\nregexp:\n%q", lit.String(), re.String())
+	}
+
+	// The automaton follows changes to the rules
+	lw.RemoveRule(lw.Rules()[0].ID)
+	lit.Reset()
+	lw.WriteString("he")
+	if have := lit.String(); have != "he" {
+		t.Errorf("removed: have %q", have)
+	}
+	if p := lw.Rules()[0].Pattern; p != "she" {
+		t.Errorf("Pattern: have %q", p)
+	}
+}
+
+func BenchmarkLiteralRules(b *testing.B) {
+	words := []string{"ERROR", "WARN", "INFO", "DEBUG", "FATAL", "GET", "POST", "PUT"}
+	for _, bc := range []struct {
+		name string
+		add  func(w *RegexpWriter, h *Hue, s string)
+	}{
+		{"Regexp", func(w *RegexpWriter, h *Hue, s string) { w.AddRuleString(h, s) }},
+		{"Literal", func(w *RegexpWriter, h *Hue, s string) { w.AddLiteralRule(h, s) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			w := NewRegexpWriter(io.Discard)
+			for i, s := range words {
+				bc.add(w, New(Red+i%7, 0), s)
+			}
+			b.SetBytes(int64(len(accessLog)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w.Write(accessLog)
+			}
+		})
+	}
+}
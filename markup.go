@@ -0,0 +1,68 @@
+package hue
+
+import "strings"
+
+// Expand converts the markup tags in s to escape sequences. A tag is a
+// style name from the registry or a spec accepted by Parse, enclosed in
+// braces. Tags accumulate until {/}, which restores the default style:
+//
+//	fmt.Printf(hue.Expand("{red}error{/}: {bold}{cyan}%s{/}\n"), name)
+//
+// The result is reset at the end if a tag is still open. Write {{ and }}
+// for literal braces. Braces that don't enclose a known style or valid
+// spec are copied unchanged. While colorization is disabled, tags are
+// removed without emitting escape sequences.
+func Expand(s string) string {
+	d := inherit.depth()
+	var b strings.Builder
+	open := false
+	for len(s) > 0 {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		if len(s) > 1 && s[1] == s[0] {
+			b.WriteByte(s[0])
+			s = s[2:]
+			continue
+		}
+		end := strings.IndexByte(s, '}')
+		if s[0] == '}' || end < 0 {
+			b.WriteByte(s[0])
+			s = s[1:]
+			continue
+		}
+
+		tag := s[1:end]
+		if tag == "/" {
+			if open && d != DepthNone {
+				b.WriteString(ASCIIReset)
+			}
+			open = false
+			s = s[end+1:]
+			continue
+		}
+		h := Style(tag)
+		if h == nil {
+			var err error
+			if h, err = Parse(tag); err != nil {
+				b.WriteByte('{')
+				s = s[1:]
+				continue
+			}
+		}
+		if codes := h.codes(d); codes != "" {
+			b.WriteString("\033[" + codes + "m")
+			open = true
+		}
+		s = s[end+1:]
+	}
+	if open {
+		b.WriteString(ASCIIReset)
+	}
+	return b.String()
+}
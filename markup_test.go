@@ -0,0 +1,29 @@
+package hue
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	Register("test.markup", &Hue{Fg: Red, Attr: Underline})
+	defer Unregister("test.markup")
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"{red}error{/}: {bold}{cyan}%s{/}", "\033[31merror\033[0m: \033[1m\033[36m%s\033[0m"},
+		{"{test.markup}x", "\033[4;31mx\033[0m"},
+		{"{{red}} {a}b}", "{red} {a}b}"},
+		{"{red on white}", "\033[31;47m\033[0m"},
+		{"plain {/} { }", "plain  { }"},
+		{"{red", "{red"},
+	} {
+		if have := Expand(tc.in); have != tc.want {
+			t.Errorf("Expand(%q): have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+
+	Disable()
+	defer Enable()
+	if have := Expand("{red}error{/}"); have != "error" {
+		t.Errorf("disabled: have %q", have)
+	}
+}
@@ -0,0 +1,153 @@
+package hue
+
+import (
+	"io"
+	"sync"
+)
+
+// Segment is a run of text and the style it is displayed in
+type Segment struct {
+	Text  string
+	Style Hue
+}
+
+// Sink is a destination of a MultiWriter and the color depth it
+// supports. A Depth of DepthNone writes plain text.
+type Sink struct {
+	W     io.Writer
+	Depth Depth
+}
+
+// MultiWriter duplicates its writes to several sinks, rendering the
+// styled text for each at its own color depth. The same output can go
+// to a truecolor terminal, a 16-color CI log and a plain file:
+//
+//	w := hue.NewMultiWriter(
+//		hue.Sink{W: os.Stdout, Depth: hue.DepthTrue},
+//		hue.Sink{W: ci, Depth: hue.Depth16},
+//		hue.Sink{W: file, Depth: hue.DepthNone},
+//	)
+//
+// Write interprets the color codes in its input, so a MultiWriter can
+// be wrapped by a Writer or RegexpWriter; WriteSegments takes styled
+// text directly. A sink's depth is used as given, whatever the
+// program-wide settings. A MultiWriter is safe for concurrent use.
+type MultiWriter struct {
+	mu    sync.Mutex
+	sinks []sink
+	lex   lexer
+	style Hue // the style selected by the input
+}
+
+// sink is a Sink and the style it was left in
+type sink struct {
+	Sink
+	cur Hue
+	buf []byte
+}
+
+// NewMultiWriter returns a MultiWriter writing to the sinks
+func NewMultiWriter(sinks ...Sink) *MultiWriter {
+	w := new(MultiWriter)
+	for _, s := range sinks {
+		w.sinks = append(w.sinks, sink{Sink: s})
+	}
+	return w
+}
+
+// Write renders p for each sink and writes it. Color codes in p are
+// translated to each sink's depth; other escape sequences, such as
+// hyperlinks, are passed through to sinks with colors. Like
+// io.MultiWriter, it stops at the first error.
+func (w *MultiWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lex.feed(p, func(b []byte, esc bool) {
+		if !esc {
+			w.text(w.style, b)
+			return
+		}
+		if params, ok := sgrParams(b); ok {
+			w.style.apply(params)
+			return
+		}
+		for i := range w.sinks {
+			if s := &w.sinks[i]; s.Depth != DepthNone {
+				s.buf = append(s.buf, b...)
+			}
+		}
+	})
+	if err := w.flush(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *MultiWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteSegments renders the styled segments for each sink and writes
+// them. Text written afterwards with Write returns to the style of the
+// input.
+func (w *MultiWriter) WriteSegments(segs ...Segment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, seg := range segs {
+		w.text(seg.Style, []byte(seg.Text))
+	}
+	return w.flush()
+}
+
+// Flush resets the style of every sink and flushes the sinks that
+// have a Flush method, as bufio.Writer does
+func (w *MultiWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.text(Hue{}, nil)
+	if err := w.flush(); err != nil {
+		return err
+	}
+	for _, s := range w.sinks {
+		if err := flushWrapped(s.W); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// text adds b in the style h to the output of every sink. The caller
+// holds w.mu.
+func (w *MultiWriter) text(h Hue, b []byte) {
+	for i := range w.sinks {
+		s := &w.sinks[i]
+		if s.cur != h {
+			if h == (Hue{}) {
+				if s.Depth != DepthNone {
+					s.buf = append(s.buf, ASCIIReset...)
+				}
+			} else if t := transition(&s.cur, &h, s.Depth); t != "" {
+				s.buf = append(s.buf, "\033["+t+"m"...)
+			}
+			s.cur = h
+		}
+		s.buf = append(s.buf, b...)
+	}
+}
+
+// flush writes the pending output of every sink. The caller holds w.mu.
+func (w *MultiWriter) flush() (err error) {
+	for i := range w.sinks {
+		s := &w.sinks[i]
+		if len(s.buf) > 0 && err == nil {
+			var n int
+			n, err = s.W.Write(s.buf)
+			if err == nil && n != len(s.buf) {
+				err = io.ErrShortWrite
+			}
+		}
+		s.buf = s.buf[:0]
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMultiWriter(t *testing.T) {
+	var truecolor, ansi, plain bytes.Buffer
+	w := NewMultiWriter(
+		Sink{W: &truecolor, Depth: DepthTrue},
+		Sink{W: &ansi, Depth: Depth16},
+		Sink{W: &plain, Depth: DepthNone},
+	)
+	orange := &Hue{Fg: RGB(255, 135, 0)}
+	NewWriter(w, orange).WriteString("warn")
+	w.WriteSegments(Segment{"a", Hue{Attr: Bold}}, Segment{"b", Hue{}})
+	w.Flush()
+
+	for _, tc := range []struct {
+		name string
+		have *bytes.Buffer
+		want string
+	}{
+		{"truecolor", &truecolor, "\033[38;2;255;135;0mwarn\033[1;39ma\033[0mb"},
+		{"16", &ansi, "\033[" + colorCode(Degrade(orange.Fg, Depth16), false) + "mwarn\033[1;39ma\033[0mb"},
+		{"none", &plain, "warnab"},
+	} {
+		if have := tc.have.String(); have != tc.want {
+			t.Errorf("%s: have %q, want %q", tc.name, have, tc.want)
+		}
+	}
+}
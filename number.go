@@ -0,0 +1,30 @@
+package hue
+
+import (
+	"fmt"
+	"io"
+)
+
+// LineNumberWriter starts every line written through it with its line
+// number, right-aligned in a gutter of its own hue:
+//
+//	w := hue.NewLineNumberWriter(os.Stdout, 4, hue.New(hue.BrightBlack, 0))
+//	w.Write(src)
+//
+// Lines may be written over several calls to Write; each line is
+// numbered once. A LineNumberWriter is safe for concurrent use.
+type LineNumberWriter struct {
+	prefixer
+}
+
+// NewLineNumberWriter returns a LineNumberWriter that writes to w.
+// Numbers start at 1 and are padded to width, followed by a space.
+func NewLineNumberWriter(w io.Writer, width int, h *Hue) *LineNumberWriter {
+	n := new(LineNumberWriter)
+	line := 0
+	n.init(w, func(d Depth) string {
+		line++
+		return string(h.encode(d, fmt.Sprintf("%*d", width, line))) + " "
+	})
+	return n
+}
@@ -0,0 +1,20 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLineNumberWriter(t *testing.T) {
+	var b bytes.Buffer
+	h := New(BrightBlack, 0)
+	w := NewLineNumberWriter(&b, 3, h)
+	w.WriteString("a\n")
+	w.WriteString("\nb")
+	w.WriteString("c\n")
+	want := string(Encode(h, "  1")) + " a\n" + string(Encode(h, "  2")) + " \n" +
+		string(Encode(h, "  3")) + " bc\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+}
@@ -0,0 +1,195 @@
+package hue
+
+import (
+	"hash/fnv"
+	"regexp"
+	"sync"
+)
+
+// Palette is an ordered collection of hues. Hues can be looked up by
+// index or name, or handed out in turn with Next. A Palette is safe
+// for concurrent use.
+type Palette struct {
+	mu    sync.Mutex
+	hues  []*Hue
+	names map[string]int
+	next  int
+}
+
+// Palettes of foreground colors that remain distinguishable to readers
+// with color vision deficiencies. The palettes are shared, so Next hands
+// out their hues in turn across the whole program; use Copy for an
+// independent sequence.
+var (
+	// PaletteOkabeIto is the Okabe-Ito palette without its black entry
+	PaletteOkabeIto = okabeIto()
+
+	// PaletteTolBright is Paul Tol's bright qualitative palette
+	PaletteTolBright = namedPalette(
+		"blue", RGB(0x44, 0x77, 0xaa),
+		"red", RGB(0xee, 0x66, 0x77),
+		"green", RGB(0x22, 0x88, 0x33),
+		"yellow", RGB(0xcc, 0xbb, 0x44),
+		"cyan", RGB(0x66, 0xcc, 0xee),
+		"purple", RGB(0xaa, 0x33, 0x77),
+		"grey", RGB(0xbb, 0xbb, 0xbb),
+	)
+
+	// PaletteViridis holds eight evenly spaced samples of the viridis
+	// color map, from dark purple to yellow
+	PaletteViridis = NewPalette(
+		New(RGB(0x44, 0x01, 0x54), 0),
+		New(RGB(0x46, 0x32, 0x7e), 0),
+		New(RGB(0x36, 0x5c, 0x8d), 0),
+		New(RGB(0x27, 0x7f, 0x8e), 0),
+		New(RGB(0x1f, 0xa1, 0x87), 0),
+		New(RGB(0x4a, 0xc1, 0x6d), 0),
+		New(RGB(0xa0, 0xda, 0x39), 0),
+		New(RGB(0xfd, 0xe7, 0x25), 0),
+	)
+
+	// PaletteColorSafe is the recommended colorblind-safe palette. It
+	// has the colors of PaletteOkabeIto, in a palette of its own.
+	PaletteColorSafe = okabeIto()
+)
+
+// okabeIto returns a new Okabe-Ito palette
+func okabeIto() *Palette {
+	return namedPalette(
+		"orange", RGB(0xe6, 0x9f, 0x00),
+		"skyblue", RGB(0x56, 0xb4, 0xe9),
+		"bluishgreen", RGB(0x00, 0x9e, 0x73),
+		"yellow", RGB(0xf0, 0xe4, 0x42),
+		"blue", RGB(0x00, 0x72, 0xb2),
+		"vermillion", RGB(0xd5, 0x5e, 0x00),
+		"reddishpurple", RGB(0xcc, 0x79, 0xa7),
+	)
+}
+
+// namedPalette returns a palette of foreground colors from alternating
+// names and color codes
+func namedPalette(a ...interface{}) *Palette {
+	p := NewPalette()
+	for i := 0; i < len(a); i += 2 {
+		p.Add(a[i].(string), New(a[i+1].(int), 0))
+	}
+	return p
+}
+
+// NewPalette returns a palette holding the unnamed hues h
+func NewPalette(h ...*Hue) *Palette {
+	p := &Palette{names: make(map[string]int)}
+	for _, v := range h {
+		p.Add("", v)
+	}
+	return p
+}
+
+// Add appends the hue h to the palette. If name is not empty, the hue
+// can be looked up with Name.
+func (p *Palette) Add(name string, h *Hue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if name != "" {
+		p.names[name] = len(p.hues)
+	}
+	p.hues = append(p.hues, h)
+}
+
+// Len returns the number of hues in the palette
+func (p *Palette) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.hues)
+}
+
+// Index returns the i'th hue. Indexes wrap around, so any integer
+// selects a hue. Index returns nil if the palette is empty.
+func (p *Palette) Index(i int) *Hue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.index(i)
+}
+
+func (p *Palette) index(i int) *Hue {
+	n := len(p.hues)
+	if n == 0 {
+		return nil
+	}
+	if i %= n; i < 0 {
+		i += n
+	}
+	return p.hues[i]
+}
+
+// Name returns the hue added under the name, or nil if there is none
+func (p *Palette) Name(name string) *Hue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, ok := p.names[name]
+	if !ok {
+		return nil
+	}
+	return p.hues[i]
+}
+
+// Next returns the palette's hues in turn, starting over after the last
+func (p *Palette) Next() *Hue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.index(p.next)
+	p.next++
+	return h
+}
+
+// Copy returns a palette with the same hues and names whose Next
+// starts over from the first hue
+func (p *Palette) Copy() *Palette {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := &Palette{hues: append([]*Hue(nil), p.hues...), names: make(map[string]int, len(p.names))}
+	for k, v := range p.names {
+		n.names[k] = v
+	}
+	return n
+}
+
+// SetPalette sets the palette AddPaletteRule takes hues from. The
+// palette's Next is used, so a palette shared with other writers hands
+// out its hues across all of them; pass a Copy for a sequence of the
+// writer's own.
+func (w *RegexpWriter) SetPalette(p *Palette) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.palette = p
+}
+
+// AddPaletteRule binds the next hue of the RegexpWriter's palette to the
+// regexp in the string s, so each rule added this way gets a color of
+// its own. The palette is set by SetPalette; by default it is a copy of
+// PaletteColorSafe. The rule has priority 0.
+func (w *RegexpWriter) AddPaletteRule(s string) RuleID {
+	re := regexp.MustCompile(s)
+	w.mu.Lock()
+	if w.palette == nil {
+		w.palette = PaletteColorSafe.Copy()
+	}
+	p := w.palette
+	w.mu.Unlock()
+	return w.AddRule(p.Next(), re)
+}
+
+// FromString returns a hue whose foreground color is derived from a
+// hash of s. The same string always produces the same color, and
+// similar strings produce unrelated colors, which helps tell apart
+// labels such as hostnames in interleaved output. Colors are kept
+// bright enough to read on a dark or light background.
+func FromString(s string) *Hue {
+	f := fnv.New32a()
+	f.Write([]byte(s))
+	x := f.Sum32()
+	hue := float64(x % 360)
+	sat := 0.55 + float64(x>>9%8)*0.03
+	lig := 0.50 + float64(x>>12%6)*0.025
+	return &Hue{Fg: HSL(hue, sat, lig)}
+}
@@ -0,0 +1,73 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPalette(t *testing.T) {
+	red, green, blue := New(Red, Default), New(Green, Default), New(Blue, Default)
+	p := NewPalette(red, green)
+	p.Add("blue", blue)
+
+	if p.Len() != 3 {
+		t.Errorf("Len: have %d", p.Len())
+	}
+	if p.Index(1) != green || p.Index(-1) != blue || p.Index(4) != green {
+		t.Errorf("Index: unexpected hues")
+	}
+	if p.Name("blue") != blue || p.Name("red") != nil {
+		t.Errorf("Name: unexpected hues")
+	}
+	for i, want := range []*Hue{red, green, blue, red} {
+		if have := p.Next(); have != want {
+			t.Errorf("Next %d: have %+v, want %+v", i, *have, *want)
+		}
+	}
+	if NewPalette().Next() != nil {
+		t.Errorf("Next: expected nil for an empty palette")
+	}
+}
+
+func TestPalettePresets(t *testing.T) {
+	p := PaletteColorSafe.Copy()
+	if p.Len() != 7 || p.Next().Fg != RGB(0xe6, 0x9f, 0x00) {
+		t.Errorf("Copy: unexpected palette")
+	}
+	if h := p.Name("vermillion"); h == nil || h.Fg != RGB(0xd5, 0x5e, 0x00) {
+		t.Errorf("Name: have %+v", h)
+	}
+	if PaletteViridis.Len() != 8 || PaletteTolBright.Len() != 7 {
+		t.Errorf("unexpected preset lengths")
+	}
+	if PaletteColorSafe == PaletteOkabeIto {
+		t.Errorf("PaletteColorSafe shares PaletteOkabeIto")
+	}
+}
+
+func TestAddPaletteRule(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddPaletteRule("a")
+	w.SetPalette(NewPalette(New(Red, 0), New(Blue, 0)))
+	w.AddPaletteRule("b")
+	w.AddPaletteRule("c")
+	w.WriteString("abc")
+	want := "\033[0;38;2;230;159;0ma\033[0;31mb\033[0;34mc" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestFromString(t *testing.T) {
+	a, b := FromString("db-1"), FromString("db-2")
+	if *a != *FromString("db-1") {
+		t.Errorf("FromString is not deterministic")
+	}
+	if *a == *b {
+		t.Errorf("FromString: %q and %q share a color", "db-1", "db-2")
+	}
+	if a.Fg&tagMask != tagRGB || a.Bg != 0 {
+		t.Errorf("FromString: unexpected hue %+v", *a)
+	}
+}
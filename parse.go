@@ -0,0 +1,217 @@
+package hue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse returns the hue described by spec. A spec names a foreground
+// color, optionally followed by a background color separated by a
+// slash or the word "on". Attribute names may appear anywhere:
+//
+//	red
+//	red/white
+//	bold red on white
+//	on blue
+//	underline
+//
+// Color names are the keys of StringToHue or CSSColors. Attribute
+// names are bold, italic, underline, strike, dim, blink, reverse and
+// conceal, or the aliases strikethrough, faint, inverse and hidden.
+// The extended underline styles are singleunderline, doubleunderline,
+// curlyunderline, dottedunderline and dashedunderline, and the
+// underline color is given as underline=color, as in "curlyunderline
+// underline=red". All names are case insensitive.
+// A bright color may also be written as two words, as in "bright red".
+// A number from 0 to 255 selects an entry of the 256-color palette,
+// and a hex string such as "#ff8800" selects a truecolor value.
+// Colors that are not named are left unset.
+func Parse(spec string) (*Hue, error) {
+	words := strings.Fields(strings.ToLower(strings.Replace(spec, "/", " on ", 1)))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("hue: empty spec")
+	}
+
+	h := new(Hue)
+	bg := false
+	bright := false
+	for _, w := range words {
+		if bright {
+			w, bright = "bright"+w, false
+		}
+		if w == "bright" {
+			bright = true
+			continue
+		}
+		if a, ok := attrByName(w); ok {
+			h.Attr |= a
+			continue
+		}
+		if st, ok := underlineStyleByName(w); ok {
+			h.UnderlineStyle = st
+			continue
+		}
+		if strings.HasPrefix(w, "underline=") {
+			c, ok := colorByName(w[len("underline="):])
+			if !ok {
+				return nil, fmt.Errorf("hue: bad spec %q: unknown underline color %q", spec, w)
+			}
+			h.UnderlineColor = c
+			continue
+		}
+		if w == "on" {
+			if bg {
+				return nil, fmt.Errorf("hue: bad spec %q: more than one background", spec)
+			}
+			bg = true
+			continue
+		}
+		c, ok := colorByName(w)
+		if !ok {
+			return nil, fmt.Errorf("hue: bad spec %q: unknown color %q", spec, w)
+		}
+		switch {
+		case bg && h.Bg == 0:
+			h.SetBg(c)
+		case !bg && h.Fg == 0:
+			h.SetFg(c)
+		default:
+			return nil, fmt.Errorf("hue: bad spec %q: too many colors", spec)
+		}
+	}
+	if bright {
+		return nil, fmt.Errorf("hue: bad spec %q: missing color after bright", spec)
+	}
+	if bg && h.Bg == 0 {
+		return nil, fmt.Errorf("hue: bad spec %q: missing background color", spec)
+	}
+	return h, nil
+}
+
+// MarshalText implements encoding.TextMarshaler. The hue is encoded as
+// a spec accepted by Parse, such as "red on default".
+func (h Hue) MarshalText() ([]byte, error) {
+	var words []string
+	for i, name := range attrNames {
+		if h.Attr&(1<<i) != 0 {
+			words = append(words, name)
+		}
+	}
+	if h.UnderlineStyle != 0 {
+		if int(h.UnderlineStyle) >= len(underlineStyleNames) {
+			return nil, fmt.Errorf("hue: can't marshal underline style %d", h.UnderlineStyle)
+		}
+		words = append(words, underlineStyleNames[h.UnderlineStyle])
+	}
+	if h.UnderlineColor != 0 {
+		s, ok := colorName(h.UnderlineColor)
+		if !ok {
+			return nil, fmt.Errorf("hue: can't marshal underline color %d", h.UnderlineColor)
+		}
+		words = append(words, "underline="+s)
+	}
+	if h.Fg != 0 {
+		s, ok := colorName(h.Fg)
+		if !ok {
+			return nil, fmt.Errorf("hue: can't marshal foreground color %d", h.Fg)
+		}
+		words = append(words, s)
+	}
+	if h.Bg != 0 {
+		s, ok := colorName(h.Bg)
+		if !ok {
+			return nil, fmt.Errorf("hue: can't marshal background color %d", h.Bg)
+		}
+		words = append(words, "on", s)
+	}
+	return []byte(strings.Join(words, " ")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is parsed
+// with Parse; empty text produces a hue with no colors set.
+func (h *Hue) UnmarshalText(text []byte) error {
+	if len(strings.TrimSpace(string(text))) == 0 {
+		*h = Hue{}
+		return nil
+	}
+	n, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*h = *n
+	return nil
+}
+
+// attrNames holds the name of each attribute in bit order
+var attrNames = [...]string{"bold", "italic", "underline", "strike", "dim", "blink", "reverse", "conceal"}
+
+// attrAliases maps alternative attribute names to those in attrNames
+var attrAliases = map[string]string{
+	"strikethrough": "strike",
+	"faint":         "dim",
+	"inverse":       "reverse",
+	"hidden":        "conceal",
+}
+
+// attrByName returns the attribute for a lower-case attribute name
+func attrByName(name string) (Attr, bool) {
+	if v, ok := attrAliases[name]; ok {
+		name = v
+	}
+	for i, v := range attrNames {
+		if v == name {
+			return 1 << i, true
+		}
+	}
+	return 0, false
+}
+
+// underlineStyleNames holds the name of each underline style
+var underlineStyleNames = [...]string{
+	UnderlineSingle: "singleunderline",
+	UnderlineDouble: "doubleunderline",
+	UnderlineCurly:  "curlyunderline",
+	UnderlineDotted: "dottedunderline",
+	UnderlineDashed: "dashedunderline",
+}
+
+// underlineStyleByName returns the underline style for a lower-case name
+func underlineStyleByName(name string) (UnderlineStyle, bool) {
+	for i, v := range underlineStyleNames {
+		if v != "" && v == name {
+			return UnderlineStyle(i), true
+		}
+	}
+	return 0, false
+}
+
+// colorByName returns the color code for a lower-case color name
+func colorByName(name string) (int, bool) {
+	if c, ok := StringToHue[name]; ok {
+		return c, true
+	}
+	if c, ok := CSSColors[name]; ok {
+		return c, true
+	}
+	if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+		return Color256(uint8(n)), true
+	}
+	if strings.HasPrefix(name, "#") {
+		c, err := Hex(name)
+		return c, err == nil
+	}
+	return 0, false
+}
+
+// colorName returns the name of the color code c as understood by Parse
+func colorName(c int) (string, bool) {
+	switch c & tagMask {
+	case tag256:
+		return strconv.Itoa(c & 0xff), true
+	case tagRGB:
+		return hex(c), true
+	}
+	s, ok := HueToString[c]
+	return s, ok
+}
@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want Hue
+	}{
+		{"red", Hue{Fg: Red}},
+		{"red/white", Hue{Fg: Red, Bg: White}},
+		{"Red on Default", Hue{Fg: Red, Bg: Default}},
+		{"on blue", Hue{Bg: Blue}},
+		{"bright red on brightwhite", Hue{Fg: BrightRed, Bg: BrightWhite}},
+		{"208/0", Hue{Fg: Color256(208), Bg: Color256(0)}},
+		{"bold cyan", Hue{Fg: Cyan, Attr: Bold}},
+		{"Underline red on white strikethrough", Hue{Fg: Red, Bg: White, Attr: Underline | Strike}},
+		{"italic", Hue{Attr: Italic}},
+		{"reverse faint hidden blink", Hue{Attr: Reverse | Dim | Conceal | Blink}},
+		{"curlyunderline underline=red", Hue{UnderlineStyle: UnderlineCurly, UnderlineColor: Red}},
+		{"#FF8800 on #000", Hue{Fg: RGB(255, 136, 0), Bg: RGB(0, 0, 0)}},
+	} {
+		h, err := Parse(tc.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.spec, err)
+			continue
+		}
+		if *h != tc.want {
+			t.Errorf("Parse(%q): have %+v, want %+v", tc.spec, *h, tc.want)
+		}
+	}
+
+	for _, spec := range []string{"", "notacolor", "red blue", "red on on blue", "red/blue/green", "red on bright", "256", "red on", "red/"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	type config struct {
+		Error Hue
+		Warn  *Hue
+	}
+	in := config{
+		Error: Hue{Fg: RGB(255, 0, 16), Bg: Default, Attr: Bold | Italic},
+		Warn:  &Hue{Bg: Color256(208), UnderlineStyle: UnderlineDouble, UnderlineColor: Color256(1)},
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Error":"bold italic #ff0010 on default","Warn":"doubleunderline underline=1 on 208"}`; string(b) != want {
+		t.Errorf("Marshal: have %s, want %s", b, want)
+	}
+
+	var out config
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Error != in.Error || *out.Warn != *in.Warn {
+		t.Errorf("Unmarshal: have %+v, want %+v", out, in)
+	}
+}
@@ -0,0 +1,119 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter prepends a colored label to every line written through
+// it, the way docker-compose labels the output of each service:
+//
+//	api := hue.NewPrefixWriter(os.Stdout, "[api] ", hue.New(hue.Cyan, 0))
+//	db := hue.NewPrefixWriter(os.Stdout, "[db]  ", hue.New(hue.Magenta, 0))
+//
+// A line may be written over several calls to Write; the label is
+// written once, before the line's first byte. Colors selected by the
+// input carry on after the label. A PrefixWriter is safe for
+// concurrent use.
+type PrefixWriter struct {
+	prefixer
+}
+
+// NewPrefixWriter returns a PrefixWriter that writes to w, starting
+// each line with label in the hue h
+func NewPrefixWriter(w io.Writer, label string, h *Hue) *PrefixWriter {
+	n := new(PrefixWriter)
+	n.init(w, func(d Depth) string {
+		return string(h.encode(d, label))
+	})
+	return n
+}
+
+// prefixer writes a prefix at the start of every line of a stream,
+// outside the colors the stream selects
+type prefixer struct {
+	mu      sync.Mutex
+	wrapped io.Writer
+	color   toggle
+	prefix  func(d Depth) string
+	blank   bool // prefix empty lines
+
+	lex   lexer
+	style Hue  // the style selected by the input
+	mid   bool // in the middle of a line
+	out   output
+}
+
+// init sets up p to write to w, starting each line with prefix
+func (p *prefixer) init(w io.Writer, prefix func(d Depth) string) {
+	p.wrapped = w
+	p.color = detect(w)
+	p.prefix = prefix
+	p.blank = true
+}
+
+// Write writes b to the underlying writer, prefixing each line. On
+// success, n is len(b).
+func (p *prefixer) Write(b []byte) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d := p.color.depth()
+	p.out.reset()
+	p.lex.feed(b, func(t []byte, esc bool) {
+		if esc {
+			if params, ok := sgrParams(t); ok {
+				p.style.apply(params)
+			}
+			p.out.add(string(t))
+			return
+		}
+		for len(t) > 0 {
+			if !p.mid && (p.blank || t[0] != '\n') {
+				p.start(d)
+			}
+			line := t
+			if i := bytes.IndexByte(t, '\n'); i >= 0 {
+				line = t[:i+1]
+				p.mid = false
+			}
+			p.out.copy(b, line)
+			t = t[len(line):]
+		}
+	})
+	if len(p.out.b) == 0 {
+		return len(b), nil
+	}
+	nb, err := p.wrapped.Write(p.out.b)
+	if err != nil {
+		return p.out.count(nb), err
+	}
+	return len(b), nil
+}
+
+// start writes the prefix for a new line. The input's style is reset
+// around it so the prefix only has its own colors.
+func (p *prefixer) start(d Depth) {
+	p.mid = true
+	colored := p.style != Hue{}
+	if colored {
+		p.out.add(ASCIIReset)
+	}
+	p.out.add(p.prefix(d))
+	if colored {
+		p.out.add(p.style.sgr(DepthTrue))
+	}
+}
+
+// WriteString is like Write, but writes the contents of s
+func (p *prefixer) WriteString(s string) (int, error) {
+	return p.Write([]byte(s))
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// colors of the prefix
+func (p *prefixer) SetEnabled(on bool) {
+	p.mu.Lock()
+	p.color.set(on)
+	p.mu.Unlock()
+}
@@ -0,0 +1,26 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var b bytes.Buffer
+	h := New(Cyan, 0)
+	w := NewPrefixWriter(&b, "[api] ", h)
+	w.WriteString("one\ntw")
+	w.WriteString("o\n\n\033[31mred\nstill red")
+	label := string(Encode(h, "[api] "))
+	want := label + "one\n" + label + "two\n" + label + "\n" +
+		"\033[31m" + ASCIIReset + label + "\033[31mred\n" + ASCIIReset + label + "\033[31mstill red"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+
+	sw := &shortWriter{n: len(label) + 2}
+	n, err := NewPrefixWriter(sw, "[api] ", h).WriteString("abc")
+	if n != 2 || err == nil {
+		t.Errorf("short write: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,55 @@
+package hue
+
+// profile is a set of rules that is not in use
+type profile struct {
+	rules []rule
+	links []linkRule
+}
+
+// UseProfile switches the RegexpWriter to the named set of rules and
+// returns the name of the profile it was using. The rules in use are
+// kept under that name, and the ones kept under name are put in their
+// place; a profile never used before has no rules. Rules added,
+// removed or changed apply to the profile in use. The writer starts
+// with the profile "".
+//
+// Rules can be set up for each profile in turn:
+//
+//	w.UseProfile("verbose")
+//	w.AddRuleString(cyan, `DEBUG.*`)
+//	w.AddRuleString(red, `ERROR.*`)
+//	w.UseProfile("errors-only")
+//	w.AddRuleString(red, `ERROR.*`)
+//
+// and swapped while other goroutines write, for example on a signal:
+//
+//	for range sigusr1 {
+//		if w.UseProfile("verbose") == "verbose" {
+//			w.UseProfile("errors-only")
+//		}
+//	}
+//
+// Each Write uses the rules of a single profile.
+func (w *RegexpWriter) UseProfile(name string) (prev string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev = w.profile
+	if name == prev {
+		return prev
+	}
+	if w.profiles == nil {
+		w.profiles = map[string]profile{}
+	}
+	w.profiles[prev] = profile{w.rules, w.links}
+	p := w.profiles[name]
+	delete(w.profiles, name)
+	w.rules, w.links, w.profile = p.rules, p.links, name
+	return prev
+}
+
+// Profile returns the name of the profile in use
+func (w *RegexpWriter) Profile() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.profile
+}
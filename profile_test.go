@@ -0,0 +1,39 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegexpWriterProfile(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red, cyan := New(Red, 0), New(Cyan, 0)
+	if prev := w.UseProfile("verbose"); prev != "" {
+		t.Errorf("first profile: %q", prev)
+	}
+	w.AddRuleString(cyan, "DEBUG")
+	w.AddRuleString(red, "ERROR")
+	w.UseProfile("errors-only")
+	w.AddRuleString(red, "ERROR")
+
+	line := "DEBUG ERROR"
+	for _, tc := range []struct {
+		profile, want string
+	}{
+		{"errors-only", "DEBUG \033[0;31mERROR" + ASCIIReset},
+		{"verbose", "\033[0;36mDEBUG" + ASCIIReset + " \033[0;31mERROR" + ASCIIReset},
+		{"", line},
+		{"errors-only", "DEBUG \033[0;31mERROR" + ASCIIReset},
+	} {
+		b.Reset()
+		w.UseProfile(tc.profile)
+		if p := w.Profile(); p != tc.profile {
+			t.Errorf("Profile: have %q, want %q", p, tc.profile)
+		}
+		w.WriteString(line)
+		if have := b.String(); have != tc.want {
+			t.Errorf("%q: have %q, want %q", tc.profile, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+)
+
+// RegexpReader colorizes the text read from an io.Reader with the
+// rules of a RegexpWriter, for pipelines that pull their input, such
+// as one feeding a pager:
+//
+//	r := hue.NewRegexpReader(os.Stdin)
+//	r.Rules().AddRuleString(red, "ERROR")
+//	r.Rules().SetLineBuffered(true)
+//	cmd := exec.Command("less", "-R")
+//	cmd.Stdin = r
+//
+// Each read of the underlying reader is colorized as if it were passed
+// to the Write method of the RegexpWriter; with line buffering, matches
+// are found however the reads split the text. A reset ends the text at
+// EOF if colorization is on.
+type RegexpReader struct {
+	w     *RegexpWriter
+	src   io.Reader
+	buf   bytes.Buffer // the colorized text not yet read
+	chunk []byte
+	err   error
+}
+
+// NewRegexpReader returns a RegexpReader reading from r
+func NewRegexpReader(r io.Reader) *RegexpReader {
+	n := &RegexpReader{src: r}
+	n.w = NewRegexpWriter(&n.buf)
+	return n
+}
+
+// Rules returns the RegexpWriter holding the rules and settings of r,
+// such as AddRuleString, SetLineBuffered and SetEnabled. It is meant
+// for configuration only; r does the writing.
+func (r *RegexpReader) Rules() *RegexpWriter {
+	return r.w
+}
+
+// Read reads colorized text into p. It returns the error of the
+// underlying reader once the text read before it has been returned.
+func (r *RegexpReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.chunk == nil {
+			r.chunk = make([]byte, 32*1024)
+		}
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			r.w.Write(r.chunk[:n])
+		}
+		if err != nil {
+			if err == io.EOF {
+				r.w.Flush()
+			}
+			r.err = err
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// Close closes the underlying reader if it is an io.Closer
+func (r *RegexpReader) Close() error {
+	if c, ok := r.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
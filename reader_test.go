@@ -0,0 +1,25 @@
+package hue
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestRegexpReader(t *testing.T) {
+	r := NewRegexpReader(iotest.OneByteReader(strings.NewReader("an ERROR\nok\n")))
+	r.Rules().AddRuleString(New(Red, 0), "ERROR")
+	r.Rules().SetLineBuffered(true)
+	b, err := io.ReadAll(iotest.HalfReader(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "an \033[0;31mERROR" + ASCIIReset + "\nok\n" + ASCIIReset
+	if have := string(b); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if n, err := r.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("after EOF: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,134 @@
+package hue
+
+import (
+	"io"
+	"sync"
+)
+
+// RecolorWriter rewrites the colors selected by the text written
+// through it, such as the output of another program, and writes the
+// result to the underlying writer. Each style the input selects is
+// passed through a mapping function, and the style it returns is
+// selected instead. For example, to show a program's blue as cyan:
+//
+//	w := hue.NewRecolorWriter(os.Stdout, hue.RemapColors(map[int]int{
+//		hue.Blue: hue.Cyan,
+//	}))
+//	cmd.Stdout = w
+//
+// Escape sequences other than SGR are passed through. Like NewWriter,
+// it writes plain text if the underlying writer is an *os.File that is
+// not a terminal, and while colorization is disabled. A RecolorWriter
+// is safe for concurrent use.
+type RecolorWriter struct {
+	mu      sync.Mutex
+	wrapped io.Writer
+	color   toggle
+	fn      func(Hue) Hue
+	lex     lexer
+	style   Hue // the style selected by the input
+	cur     Hue // the style selected in the output
+	out     output
+}
+
+// NewRecolorWriter returns a RecolorWriter writing to w that selects
+// fn(h) wherever the input selects the style h. The styles passed to
+// fn have colors reset to the default unset.
+func NewRecolorWriter(w io.Writer, fn func(h Hue) Hue) *RecolorWriter {
+	return &RecolorWriter{wrapped: w, color: detect(w), fn: fn}
+}
+
+// RemapColors returns a mapping function for NewRecolorWriter that
+// replaces the foreground, background and underline colors found in m
+// with the colors they map to. Other colors and the attributes are
+// kept.
+func RemapColors(m map[int]int) func(Hue) Hue {
+	remap := func(c int) int {
+		if to, ok := m[c]; ok {
+			return to
+		}
+		return c
+	}
+	return func(h Hue) Hue {
+		h.Fg, h.Bg, h.UnderlineColor = remap(h.Fg), remap(h.Bg), remap(h.UnderlineColor)
+		return h
+	}
+}
+
+// Write rewrites the colors of p and writes it to the underlying
+// writer. A sequence may be split across writes. On success, n is
+// len(p).
+func (w *RecolorWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	d := w.color.depth()
+	w.out.reset()
+	w.lex.feed(p, func(b []byte, esc bool) {
+		if !esc {
+			w.choose(d, w.fn(w.style))
+			w.out.copy(p, b)
+			return
+		}
+		if params, ok := sgrParams(b); ok {
+			w.style.apply(params)
+			return
+		}
+		if d != DepthNone {
+			w.out.add(string(b))
+		}
+	})
+	if len(w.out.b) == 0 {
+		return len(p), nil
+	}
+	nb, err := w.wrapped.Write(w.out.b)
+	if err != nil {
+		return w.out.count(nb), err
+	}
+	return len(p), nil
+}
+
+// choose changes the style of the output to h. The caller holds w.mu.
+func (w *RecolorWriter) choose(d Depth, h Hue) {
+	if h == w.cur || d == DepthNone {
+		return
+	}
+	if h == (Hue{}) {
+		w.out.add(ASCIIReset)
+	} else if t := transition(&w.cur, &h, d); t != "" {
+		w.out.add("\033[" + t + "m")
+	}
+	w.cur = h
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *RecolorWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush writes a reset if the output is left colored, and flushes the
+// underlying writer if it has a Flush method
+func (w *RecolorWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cur != (Hue{}) {
+		w.cur = Hue{}
+		if _, err := io.WriteString(w.wrapped, ASCIIReset); err != nil {
+			return err
+		}
+	}
+	return flushWrapped(w.wrapped)
+}
+
+// Close flushes the RecolorWriter. It does not close the underlying
+// writer.
+func (w *RecolorWriter) Close() error {
+	return w.Flush()
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// RecolorWriter. When disabled, it writes plain text.
+func (w *RecolorWriter) SetEnabled(on bool) {
+	w.mu.Lock()
+	w.color.set(on)
+	w.mu.Unlock()
+}
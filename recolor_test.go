@@ -0,0 +1,28 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecolorWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRecolorWriter(&b, RemapColors(map[int]int{Blue: Cyan, Red: RGB(255, 128, 0)}))
+	w.WriteString("\033[1;34mblue\033[0m \033[4")
+	w.WriteString("1mred bg\033[0m \033]8;;http://x\033\\\033[32mgreen\033[m")
+	want := "\033[1;36mblue" + ASCIIReset + " \033[48;2;255;128;0mred bg" + ASCIIReset + " \033]8;;http://x\033\\\033[32mgreen"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+	w.Flush()
+	if have := b.String(); have != want+ASCIIReset {
+		t.Errorf("Flush: have %q", have)
+	}
+
+	b.Reset()
+	w.SetEnabled(false)
+	w.WriteString("\033[34mplain\033]8;;http://x\033\\")
+	if have := b.String(); have != "plain" {
+		t.Errorf("disabled: have %q", have)
+	}
+}
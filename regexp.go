@@ -0,0 +1,929 @@
+package hue
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"sync"
+)
+
+// RegexpWriter implements colorization for a io.Writer object by processing
+// a set of rules. Rules are hue objects assocated with regular expressions.
+// A RegexpWriter is safe for concurrent use: rules may be added and
+// removed while other goroutines write, and each change applies from
+// the next Write.
+type RegexpWriter struct {
+	mu      sync.Mutex
+	rules   []rule
+	links   []linkRule
+	wrapped io.Writer
+	color   toggle
+	policy  OverlapPolicy
+	lastID  RuleID
+	slots   []slot   // the hues of the rules, for the current Write
+	huemap  []int    // the slot coloring each byte, plus one
+	lens    []int    // the length of the match coloring each byte
+	seqs    []string // the escape sequence selecting each slot's hue
+	out     output
+
+	grep      bool
+	invert    *Hue
+	drops     []span // the lines to drop in grep mode
+	replaced  []replacement
+	buffered  bool
+	multiline bool
+	partial   []byte // the text after the last newline, if buffered
+	max       int    // the most text to hold back, or 0 for the default
+
+	lits     *literals          // the automaton for the literal rules
+	profile  string             // the name of the rules in use
+	profiles map[string]profile // the rules not in use, by name
+	palette  *Palette           // the hues of AddPaletteRule
+}
+
+type rule struct {
+	*Hue
+	*regexp.Regexp
+	id       RuleID
+	priority int
+	groups   map[int]*Hue            // if set, the hues of capture groups instead
+	keys     []int                   // the capture groups in groups, in order
+	fn       func(match []byte) *Hue // if set, chooses the hue of each match
+	line     bool                    // color the lines of the matches
+	replace  bool                    // replace the matches with repl
+	repl     string
+	off      bool           // disabled with DisableRule
+	partial  *regexp.Regexp // the open matches at the end of text, for multiline mode
+	lit      string         // if Regexp is nil, the text the rule matches
+	matches  int64          // the number of matches written
+	bytes    int64          // the length of those matches
+}
+
+// OverlapPolicy selects which rule colors text matched by more than one
+// rule of the same priority
+type OverlapPolicy int
+
+const (
+	// OverlapLast colors the text with the rule added last
+	OverlapLast OverlapPolicy = iota
+	// OverlapFirst colors the text with the rule added first
+	OverlapFirst
+	// OverlapLongest colors the text with the rule with the longest
+	// match, and the rule added last if the matches are equally long
+	OverlapLongest
+)
+
+// NewRegexpWriter returns a new RegexpWriter. Like NewWriter, it writes
+// plain text if w is an *os.File that is not a terminal.
+func NewRegexpWriter(w io.Writer) *RegexpWriter {
+	n := new(RegexpWriter)
+	n.wrapped = w
+	n.color = detect(w)
+	return n
+}
+
+// AddRuleStringPOSIX binds a hue to the POSIX regexp in the string 's'.
+// Similar to AddRule, except the caller passes in an uncompiled POSIX regexp.
+func (w *RegexpWriter) AddRuleStringPOSIX(h *Hue, s string) RuleID {
+	re := regexp.MustCompilePOSIX(s)
+	return w.AddRule(h, re)
+}
+
+// AddRuleString binds a hue to the regexp in the string 's'.
+// Similar to AddRule, except the caller passes in an uncompiled regexp.
+func (w *RegexpWriter) AddRuleString(h *Hue, s string) RuleID {
+	re := regexp.MustCompile(s)
+	return w.AddRule(h, re)
+}
+
+// AddRuleStringErr is like AddRuleString, except it returns an error
+// instead of panicking if s is not a valid regexp, and adds no rule.
+// It suits patterns taken from configuration files or flags.
+func (w *RegexpWriter) AddRuleStringErr(h *Hue, s string) (RuleID, error) {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return 0, fmt.Errorf("hue: %w", err)
+	}
+	return w.AddRule(h, re), nil
+}
+
+// AddRuleStringPOSIXErr is like AddRuleStringPOSIX, except it returns
+// an error instead of panicking if s is not a valid POSIX regexp.
+func (w *RegexpWriter) AddRuleStringPOSIXErr(h *Hue, s string) (RuleID, error) {
+	re, err := regexp.CompilePOSIX(s)
+	if err != nil {
+		return 0, fmt.Errorf("hue: %w", err)
+	}
+	return w.AddRule(h, re), nil
+}
+
+// AddRule binds a hue to a regular expression. The rule has priority 0.
+// It returns the rule's ID, which RemoveRule takes.
+func (w *RegexpWriter) AddRule(h *Hue, re *regexp.Regexp) RuleID {
+	return w.AddRulePriority(h, re, 0)
+}
+
+// AddRulePriority binds a hue to a regular expression with a priority.
+// Where the matches of rules overlap, the rule with the highest priority
+// colors the text; the overlap policy decides between rules of the
+// same priority.
+func (w *RegexpWriter) AddRulePriority(h *Hue, re *regexp.Regexp, priority int) RuleID {
+	return w.add(rule{Hue: h, Regexp: re, priority: priority})
+}
+
+// RuleID identifies a rule of a RegexpWriter
+type RuleID int
+
+// add adds r to the rules and returns its new ID
+func (w *RegexpWriter) add(r rule) RuleID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r = w.newRule(r)
+	w.rules = append(w.rules, r)
+	return r.id
+}
+
+// newRule returns r with a new ID. The caller holds w.mu.
+func (w *RegexpWriter) newRule(r rule) rule {
+	w.lastID++
+	r.id = w.lastID
+	return r
+}
+
+// rule returns the rule with the given ID, or nil
+func (w *RegexpWriter) rule(id RuleID) *rule {
+	for i := range w.rules {
+		if w.rules[i].id == id {
+			return &w.rules[i]
+		}
+	}
+	return nil
+}
+
+// SetWholeLine sets whether the rule with the given ID colors the whole
+// line containing each of its matches, not counting the newline,
+// instead of the matched text alone:
+//
+//	w.SetWholeLine(w.AddRuleString(red, "FATAL"), true)
+//
+// Without line buffering, the line ends at the edges of each Write. It
+// reports whether there is such a rule.
+func (w *RegexpWriter) SetWholeLine(id RuleID, on bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r := w.rule(id)
+	if r == nil {
+		return false
+	}
+	r.line = on
+	return true
+}
+
+// EnableRule turns the rule with the given ID back on after
+// DisableRule. It reports whether there is such a rule.
+func (w *RegexpWriter) EnableRule(id RuleID) bool {
+	return w.setOff(id, false)
+}
+
+// DisableRule mutes the rule with the given ID until EnableRule is
+// called. A disabled rule keeps its place among the rules and its
+// priority, but colors, replaces and counts nothing. It reports whether
+// there is such a rule.
+func (w *RegexpWriter) DisableRule(id RuleID) bool {
+	return w.setOff(id, true)
+}
+
+// setOff sets whether the rule with the given ID is disabled
+func (w *RegexpWriter) setOff(id RuleID, off bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r := w.rule(id)
+	if r == nil {
+		return false
+	}
+	r.off = off
+	return true
+}
+
+// RuleStats holds the number of matches of a rule and their total
+// length in bytes
+type RuleStats struct {
+	ID      RuleID
+	Matches int64
+	Bytes   int64
+}
+
+// Stats returns the match statistics of each rule, in the order the
+// rules were added. Matches are counted whether colorization is on or
+// off; empty matches are not counted.
+func (w *RegexpWriter) Stats() []RuleStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stats := make([]RuleStats, len(w.rules))
+	for i, r := range w.rules {
+		stats[i] = RuleStats{r.id, r.matches, r.bytes}
+	}
+	return stats
+}
+
+// ResetStats sets the match statistics of every rule to zero
+func (w *RegexpWriter) ResetStats() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.rules {
+		w.rules[i].matches, w.rules[i].bytes = 0, 0
+	}
+}
+
+// RemoveRule deletes the rule with the given ID. It reports whether
+// there was such a rule.
+func (w *RegexpWriter) RemoveRule(id RuleID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, r := range w.rules {
+		if r.id == id {
+			w.rules = append(w.rules[:i], w.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AddRuleGroups binds hues to capture groups of a regular expression,
+// coloring only those groups of each match instead of the whole match.
+// For example, to color the status code and latency of access log lines:
+//
+//	re := regexp.MustCompile(`" (\d{3}) .* (\d+ms)$`)
+//	w.AddRuleGroups(re, map[int]*hue.Hue{1: red, 2: cyan})
+//
+// Group 0 is the whole match. Where groups nest, the inner group, which
+// has the higher number, colors the text. The rule has priority 0.
+// AddRuleGroups panics if re has no group with one of the numbers.
+func (w *RegexpWriter) AddRuleGroups(re *regexp.Regexp, groups map[int]*Hue) RuleID {
+	keys := make([]int, 0, len(groups))
+	for g := range groups {
+		if g < 0 || g > re.NumSubexp() {
+			panic(fmt.Sprintf("hue: AddRuleGroups: no group %d in %q", g, re))
+		}
+		keys = append(keys, g)
+	}
+	sort.Ints(keys)
+	return w.add(rule{Regexp: re, groups: groups, keys: keys})
+}
+
+// AddRuleFunc binds a regular expression to a function choosing the hue
+// of each match from the matched text. For example, to color HTTP
+// status codes by class with a single rule:
+//
+//	w.AddRuleFunc(regexp.MustCompile(`\b[2-5]\d\d\b`), func(m []byte) *hue.Hue {
+//		switch m[0] {
+//		case '2':
+//			return green
+//		case '4':
+//			return brown
+//		case '5':
+//			return red
+//		}
+//		return nil
+//	})
+//
+// A nil hue leaves the match uncolored. The slice passed to fn must not
+// be retained, and fn must not call methods of w. The rule has
+// priority 0.
+func (w *RegexpWriter) AddRuleFunc(re *regexp.Regexp, fn func(match []byte) *Hue) RuleID {
+	return w.add(rule{Regexp: re, fn: fn})
+}
+
+// SetOverlapPolicy sets which of the rules with the same priority
+// colors text they both match. The default is OverlapLast.
+func (w *RegexpWriter) SetOverlapPolicy(p OverlapPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.policy = p
+}
+
+// span is the text p[start:end]
+type span struct{ start, end int }
+
+// dropLines records in drops the lines of p, with their newlines, that
+// no rule colors
+func (w *RegexpWriter) dropLines(p []byte) {
+	for start := 0; start < len(p); {
+		end := len(p)
+		if k := bytes.IndexByte(p[start:], '\n'); k >= 0 {
+			end = start + k + 1
+		}
+		colored := false
+		for _, sl := range w.huemap[start:end] {
+			if sl != 0 {
+				colored = true
+				break
+			}
+		}
+		if !colored {
+			if n := len(w.drops); n > 0 && w.drops[n-1].end == start {
+				w.drops[n-1].end = end
+			} else {
+				w.drops = append(w.drops, span{start, end})
+			}
+		}
+		start = end
+	}
+}
+
+// SetGrep sets whether the RegexpWriter drops the lines that no rule
+// colors, like a grep that highlights its matches. Lines are dropped
+// even while colorization is off. Without line buffering, a line split
+// between writes is judged in pieces.
+func (w *RegexpWriter) SetGrep(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.grep = on
+}
+
+// SetInvert sets the RegexpWriter to color the text that no rule colors
+// with h, and to write the text the rules match uncolored. It can dim
+// the boilerplate around the interesting parts of each line:
+//
+//	w.AddRuleString(nil, `(ERROR|WARN).*`)
+//	w.SetInvert(&hue.Hue{Attr: hue.Dim})
+//
+// The rules' hues are not used while inverted, so they may be nil. A
+// nil h turns inversion off.
+func (w *RegexpWriter) SetInvert(h *Hue) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.invert = h
+}
+
+// slot is a hue a rule colors text with: its own, or that of one of its
+// capture groups
+type slot struct {
+	rule int
+	h    *Hue
+}
+
+// paint fills huemap with the slot coloring each byte of p, plus one,
+// or 0 for bytes matched by no rule. In a dry run, the rule statistics
+// are left alone and replacement rules color their matches in p.
+func (w *RegexpWriter) paint(p []byte, dry bool) {
+	if cap(w.huemap) < len(p) {
+		w.huemap = make([]int, len(p))
+	}
+	w.huemap = w.huemap[:len(p)]
+	clear(w.huemap)
+	w.lens = append(w.lens[:0], w.huemap...)
+
+	mark := func(i, slot, start, end, n int) {
+		if w.rules[i].line {
+			start = bytes.LastIndexByte(p[:start], '\n') + 1
+			if k := bytes.IndexByte(p[end:], '\n'); k >= 0 {
+				end += k
+			} else {
+				end = len(p)
+			}
+		}
+		for j := start; j < end; j++ {
+			if w.beats(i, n, w.huemap[j], w.lens[j]) {
+				w.huemap[j], w.lens[j] = slot+1, n
+			}
+		}
+	}
+	count := func(i, n int) {
+		if dry || n == 0 {
+			// An empty match colors nothing
+			return
+		}
+		w.rules[i].matches++
+		w.rules[i].bytes += int64(n)
+	}
+	w.slots = w.slots[:0]
+	lits := w.literals()
+	if lits != nil {
+		lits.scan(p)
+	}
+	lit := 0 // the index of the next literal rule in lits
+	for i, r := range w.rules {
+		if r.Regexp == nil {
+			lit++
+		}
+		if r.off {
+			continue
+		}
+		base := len(w.slots)
+		if r.Regexp == nil {
+			w.slots = append(w.slots, slot{i, r.Hue})
+			for _, m := range lits.found[lit-1] {
+				count(i, m[1]-m[0])
+				mark(i, base, m[0], m[1], m[1]-m[0])
+			}
+			continue
+		}
+		if r.replace && !dry {
+			w.slots = append(w.slots, slot{i, r.Hue})
+			for _, rp := range w.replaced {
+				if rp.rule == i {
+					count(i, rp.end-rp.start)
+					mark(i, base, rp.start, rp.end, rp.end-rp.start)
+				}
+			}
+			continue
+		}
+		if r.fn != nil {
+			slots := map[*Hue]int{}
+			for _, m := range r.FindAllIndex(p, -1) {
+				count(i, m[1]-m[0])
+				h := r.fn(p[m[0]:m[1]])
+				if h == nil {
+					continue
+				}
+				sl, ok := slots[h]
+				if !ok {
+					sl = len(w.slots)
+					slots[h] = sl
+					w.slots = append(w.slots, slot{i, h})
+				}
+				mark(i, sl, m[0], m[1], m[1]-m[0])
+			}
+			continue
+		}
+		if r.groups == nil {
+			w.slots = append(w.slots, slot{i, r.Hue})
+			for _, m := range r.FindAllIndex(p, -1) {
+				count(i, m[1]-m[0])
+				mark(i, base, m[0], m[1], m[1]-m[0])
+			}
+			continue
+		}
+		for _, g := range r.keys {
+			w.slots = append(w.slots, slot{i, r.groups[g]})
+		}
+		for _, m := range r.FindAllSubmatchIndex(p, -1) {
+			count(i, m[1]-m[0])
+			for k, g := range r.keys {
+				if 2*g+1 < len(m) && m[2*g] >= 0 {
+					mark(i, base+k, m[2*g], m[2*g+1], m[1]-m[0])
+				}
+			}
+		}
+	}
+}
+
+// swap colors the unmatched text with the inverted hue, if one is set,
+// and leaves the matched text uncolored
+func (w *RegexpWriter) swap() {
+	if w.invert == nil {
+		return
+	}
+	w.slots = append(w.slots, slot{-1, w.invert})
+	for i, sl := range w.huemap {
+		if sl == 0 {
+			w.huemap[i] = len(w.slots)
+		} else {
+			w.huemap[i] = 0
+		}
+	}
+}
+
+// beats reports whether rule i, with a match n bytes long, colors a
+// byte colored by slot owner-1 with a match olen long. Rules are
+// applied in order, so i comes after the owner's rule, or is the same
+// rule coloring a capture group nested in another.
+func (w *RegexpWriter) beats(i, n, owner, olen int) bool {
+	if owner == 0 || w.slots[owner-1].rule == i {
+		return true
+	}
+	r, o := w.rules[i], w.rules[w.slots[owner-1].rule]
+	if r.priority != o.priority {
+		return r.priority > o.priority
+	}
+	switch w.policy {
+	case OverlapFirst:
+		return false
+	case OverlapLongest:
+		return n >= olen
+	}
+	return true
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// RegexpWriter. When disabled, the RegexpWriter writes plain text.
+func (w *RegexpWriter) SetEnabled(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.color.set(on)
+}
+
+// FlushRules deletes all rules added with AddRule and AddLinkRule from Writer.
+// Only the rules of the profile in use are deleted.
+func (w *RegexpWriter) FlushRules() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rules = nil
+	w.links = nil
+}
+
+// RuleInfo describes a rule of a RegexpWriter
+type RuleInfo struct {
+	ID       RuleID
+	Pattern  string // the source of the regular expression
+	Hue      *Hue   // nil for rules added with AddRuleGroups or AddRuleFunc
+	Priority int
+	Enabled  bool
+}
+
+// String formats the rule on one line, as in
+//
+//	#3 "ERROR.*" bold red priority 1
+func (r RuleInfo) String() string {
+	spec := "-"
+	if r.Hue != nil {
+		if b, err := r.Hue.MarshalText(); err == nil && len(b) > 0 {
+			spec = string(b)
+		}
+	}
+	s := fmt.Sprintf("#%d %q %s", r.ID, r.Pattern, spec)
+	if r.Priority != 0 {
+		s += fmt.Sprintf(" priority %d", r.Priority)
+	}
+	if !r.Enabled {
+		s += " disabled"
+	}
+	return s
+}
+
+// Rules returns the rules of the profile in use, in the order they
+// were added. The hues are copies.
+func (w *RegexpWriter) Rules() []RuleInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rules := make([]RuleInfo, len(w.rules))
+	for i, r := range w.rules {
+		rules[i] = RuleInfo{
+			ID:       r.id,
+			Pattern:  r.pattern(),
+			Priority: r.priority,
+			Enabled:  !r.off,
+		}
+		if r.Hue != nil {
+			h := *r.Hue
+			rules[i].Hue = &h
+		}
+	}
+	return rules
+}
+
+// PrintRules prints out the rules, one per line.
+//
+// Deprecated: Use Rules, which returns them.
+func (w *RegexpWriter) PrintRules() {
+	for _, r := range w.Rules() {
+		fmt.Println(r)
+	}
+}
+
+// Flush writes any text held back by line buffering, then a reset, to
+// the underlying writer, and flushes it if it has a Flush method, as
+// bufio.Writer does.
+func (w *RegexpWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		_, err := w.write(w.partial)
+		w.partial = w.partial[:0]
+		if err != nil {
+			return err
+		}
+	}
+	return flushReset(w.wrapped, w.color.depth())
+}
+
+// ReadFrom colorizes and writes the contents of r to the underlying
+// writer until EOF, reusing one buffer for the reads. Each read is
+// colorized as if passed to Write; without line buffering, a match
+// split between two reads is not found. It implements io.ReaderFrom, so io.Copy uses it.
+func (w *RegexpWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	return readFrom(w, r)
+}
+
+// Close flushes the RegexpWriter, writing any text held back by line
+// buffering and a final reset, then closes the underlying writer if it
+// is an io.Closer
+func (w *RegexpWriter) Close() error {
+	return closeAfter(w.Flush(), w.wrapped)
+}
+
+// closeAfter closes w if it is an io.Closer and returns err, the error
+// of flushing w, or else the error from Close
+func closeAfter(err error, w io.Writer) error {
+	if c, ok := w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// WriteString is similar to Write, except it writes a string to the underlying
+// buffer instead of a byte slice.
+func (w *RegexpWriter) WriteString(s string) (n int, err error) {
+	return w.Write([]byte(s))
+}
+
+// Write writes the contents of p into the buffer after processesing the regexp
+// rules added to Writer with AddRule. Write colorizes the contents as it writes
+// to the underlying writer object. Where matches overlap, the rule with the
+// highest priority wins, then the one chosen by the overlap policy. The output
+// goes to the underlying writer in a single Write; as io.Writer requires, n
+// counts the bytes of p written, not the color codes around them.
+//
+// With line buffering on, see SetLineBuffered, Write holds back the text
+// after the last newline in p until the next Write or Flush.
+func (w *RegexpWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.buffered && !w.multiline {
+		return w.write(p)
+	}
+	carried := len(w.partial)
+	data := append(w.partial, p...)
+	k := bytes.LastIndexByte(data, '\n') + 1
+	if w.multiline {
+		k = w.holdFrom(data[:k])
+	}
+	if len(data)-k > w.maxHold() {
+		// Too much to hold: color what there is
+		k = len(data)
+	}
+	if k == 0 {
+		w.partial = data
+		return len(p), nil
+	}
+	n, err = w.write(data[:k])
+	w.partial = append(data[:0], data[k:]...)
+	if err != nil {
+		return max(0, n-carried), err
+	}
+	return len(p), nil
+}
+
+// SetLineBuffered sets whether the RegexpWriter applies its rules to
+// complete lines only. With line buffering on, a match is found however
+// the text is split between writes, as long as it doesn't span lines.
+// Text after the last newline is written by the next Write that ends
+// its line, or by Flush, or once there is more than SetMaxBuffer allows.
+func (w *RegexpWriter) SetLineBuffered(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buffered = on
+}
+
+// SetMultiline sets whether the RegexpWriter holds back text that a
+// match spanning lines might still cover, so that rules can match
+// multi-line text, such as stack traces or SQL statements, as a unit
+// however it is split between writes:
+//
+//	w.AddRuleString(red, `(?m)^Exception.*\n(\s+at .*\n)*`)
+//	w.SetMultiline(true)
+//
+// Complete lines are written once no match could cover them. The lines
+// of a match reaching the end of the text, and those that more text
+// could turn into a match, such as "BEGIN\n" for the pattern
+// "BEGIN\nEND", are held back and matched again with the text of the
+// next Write, until Flush or until there is more than SetMaxBuffer
+// allows. Multiline mode implies line buffering.
+func (w *RegexpWriter) SetMultiline(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.multiline = on
+}
+
+// DefaultMaxBuffer is the most text a line-buffered RegexpWriter holds
+// back by default
+const DefaultMaxBuffer = 64 << 10
+
+// SetMaxBuffer sets the most text, in bytes, that line buffering and
+// multiline mode hold back between writes; n <= 0 restores
+// DefaultMaxBuffer. When a Write leaves more than n bytes held, as in a
+// stream with no newlines, all of it is colorized and written at once,
+// as Flush would: matches are found within the text held, and a match
+// continuing past it is colored in two parts, or not found.
+func (w *RegexpWriter) SetMaxBuffer(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.max = n
+}
+
+// maxHold returns the most text w holds back
+func (w *RegexpWriter) maxHold() int {
+	if w.max <= 0 {
+		return DefaultMaxBuffer
+	}
+	return w.max
+}
+
+// holdFrom returns the start of the lines of p, a run of complete lines,
+// that a match could still cover: those of a match reaching the end of
+// p, or of text that more text could turn into one. Those lines are
+// held back in multiline mode.
+func (w *RegexpWriter) holdFrom(p []byte) int {
+	cut := len(p)
+	for i := range w.rules {
+		r := &w.rules[i]
+		if r.off {
+			continue
+		}
+		if r.partial == nil {
+			r.partial = partialRegexp(r.pattern())
+		}
+		if m := r.partial.FindIndex(p); m != nil {
+			cut = min(cut, m[0])
+		}
+	}
+	return bytes.LastIndexByte(p[:cut], '\n') + 1
+}
+
+// partialRegexp returns a regular expression whose leftmost match in
+// a text is the earliest run at its end that starts a match of pat
+func partialRegexp(pat string) *regexp.Regexp {
+	re, err := syntax.Parse(pat, syntax.Perl)
+	if err == nil {
+		tail := &syntax.Regexp{Op: syntax.OpEndText}
+		re = &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{prefixes(re), tail}}
+		if p, err := regexp.Compile(re.String()); err == nil {
+			p.Longest()
+			return p
+		}
+	}
+	// Hold only the matches reaching the end
+	p := regexp.MustCompile(`(?:` + pat + `)\z`)
+	p.Longest()
+	return p
+}
+
+// prefixes returns a regular expression matching the prefixes of the
+// matches of re, including the empty one. Counted repetitions match
+// any number of times, so it may match more.
+func prefixes(re *syntax.Regexp) *syntax.Regexp {
+	node := func(op syntax.Op, sub ...*syntax.Regexp) *syntax.Regexp {
+		return &syntax.Regexp{Op: op, Flags: re.Flags, Sub: sub}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		// a(b(c)?)?, from the last rune out
+		var p *syntax.Regexp
+		for i := len(re.Rune) - 1; i >= 0; i-- {
+			lit := &syntax.Regexp{Op: syntax.OpLiteral, Flags: re.Flags, Rune: re.Rune[i : i+1]}
+			if p != nil {
+				lit = node(syntax.OpConcat, lit, p)
+			}
+			p = node(syntax.OpQuest, lit)
+		}
+		if p == nil {
+			return node(syntax.OpEmptyMatch)
+		}
+		return p
+	case syntax.OpCharClass, syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return node(syntax.OpQuest, re)
+	case syntax.OpCapture, syntax.OpQuest:
+		return prefixes(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		star := node(syntax.OpStar, re.Sub[0])
+		return node(syntax.OpConcat, star, prefixes(re.Sub[0]))
+	case syntax.OpConcat:
+		// a prefix of one part, after all the parts before it
+		alt := node(syntax.OpAlternate)
+		for i, sub := range re.Sub {
+			c := node(syntax.OpConcat, append(re.Sub[:i:i], prefixes(sub))...)
+			alt.Sub = append(alt.Sub, c)
+		}
+		return alt
+	case syntax.OpAlternate:
+		alt := node(syntax.OpAlternate)
+		for _, sub := range re.Sub {
+			alt.Sub = append(alt.Sub, prefixes(sub))
+		}
+		return alt
+	}
+	// The empty matches and assertions
+	return re
+}
+
+// write colorizes and writes p
+func (w *RegexpWriter) write(p []byte) (n int, err error) {
+	d := w.color.depth()
+	replace := w.hasReplace()
+	if d == DepthNone && !w.grep && !replace {
+		if len(w.rules) > 0 {
+			// Only for the statistics
+			w.paint(p, false)
+		}
+		return w.wrapped.Write(p)
+	}
+
+	if replace {
+		in := len(p)
+		p = w.rewrite(p)
+		defer func() {
+			// n can't be told apart in the rewritten text
+			if err != nil {
+				n = 0
+			} else {
+				n = in
+			}
+		}()
+	}
+	w.paint(p, false)
+	w.drops = w.drops[:0]
+	if w.grep {
+		w.dropLines(p)
+	}
+	w.swap()
+	w.seqs = w.seqs[:0]
+	for _, sl := range w.slots {
+		// Reset first so attributes of the previous rule don't carry over
+		seq := ASCIIReset
+		if sl.h != nil {
+			if codes := sl.h.codes(d); codes != "" {
+				seq = "\033[0;" + codes + "m"
+			}
+		}
+		w.seqs = append(w.seqs, seq)
+	}
+
+	var links []linkSpan
+	if d != DepthNone {
+		links = w.linkSpans(p)
+	}
+	linked := false
+	hue := 0
+	drops := w.drops
+
+	w.out.reset()
+	for i := 0; i < len(p); {
+		if len(drops) > 0 && i == drops[0].start {
+			if linked {
+				w.out.add(OSC8End)
+				linked = false
+			}
+			i = drops[0].end
+			drops = drops[1:]
+			for len(links) > 0 && links[0].start < i {
+				links = links[1:]
+			}
+			continue
+		}
+		if linked && i == links[0].end {
+			w.out.add(OSC8End)
+			linked, links = false, links[1:]
+		}
+		if !linked && len(links) > 0 && i == links[0].start {
+			w.out.add(linkStart(links[0].url))
+			linked = true
+		}
+		if w.huemap[i] != hue && d != DepthNone {
+			hue = w.huemap[i]
+			if hue == 0 {
+				w.out.add(ASCIIReset)
+			} else {
+				w.out.add(w.seqs[hue-1])
+			}
+		}
+
+		// The run continues to the next change of rule or link
+		j := i + 1
+		for j < len(p) && (w.huemap[j] == hue || d == DepthNone) {
+			j++
+		}
+		if len(drops) > 0 {
+			j = min(j, drops[0].start)
+		}
+		if len(links) > 0 {
+			if linked {
+				j = min(j, links[0].end)
+			} else if links[0].start > i {
+				j = min(j, links[0].start)
+			}
+		}
+		w.out.copy(p, p[i:j])
+		i = j
+	}
+	if linked {
+		w.out.add(OSC8End)
+	}
+	if hue != 0 {
+		w.out.add(ASCIIReset)
+	}
+
+	if len(w.out.b) == 0 {
+		return len(p), nil
+	}
+	nb, err := w.wrapped.Write(w.out.b)
+	if err != nil {
+		return w.out.count(nb), err
+	}
+	return len(p), nil
+}
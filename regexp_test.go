@@ -0,0 +1,439 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// accessLog is a sample of log lines for the benchmarks
+var accessLog = []byte(strings.Repeat(
+	"2024-01-02 03:04:05 INFO  GET /api/v1/users 200 12ms\n"+
+		"2024-01-02 03:04:06 ERROR GET /api/v1/items 500 340ms\n", 1000))
+
+// countWriter counts the calls to Write
+type countWriter struct {
+	bytes.Buffer
+	calls int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.calls++
+	return w.Buffer.Write(p)
+}
+
+func TestRegexpWriterRuns(t *testing.T) {
+	var cw countWriter
+	w := NewRegexpWriter(&cw)
+	red := New(Red, 0)
+	w.AddRuleString(red, "b+")
+	w.AddLinkRule(regexp.MustCompile("c"), "http://c")
+	n, err := w.WriteString("abbcd")
+	if n != 5 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	if cw.calls != 1 {
+		t.Errorf("%d writes to the underlying writer, want 1", cw.calls)
+	}
+	want := "a\033[0;31mbb" + linkStart("http://c") + ASCIIReset + "c" + OSC8End + "d"
+	if have := cw.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func BenchmarkRegexpWriter(b *testing.B) {
+	var buf bytes.Buffer
+	for _, bc := range []struct {
+		name string
+		w    io.Writer
+	}{
+		{"Discard", io.Discard},
+		{"Buffer", &buf},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			w := NewRegexpWriter(bc.w)
+			w.AddRuleString(New(Red, 0), `ERROR`)
+			w.AddRuleString(New(Cyan, 0), `\d+ms`)
+			w.AddRuleString(&Hue{Attr: Bold}, `/api/\S+`)
+			b.SetBytes(int64(len(accessLog)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				w.Write(accessLog)
+			}
+		})
+	}
+}
+
+func TestRegexpWriterLineBuffered(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.SetLineBuffered(true)
+	red := New(Red, 0)
+	w.AddRuleString(red, "ERROR")
+	w.WriteString("ok\nERR")
+	if have := b.String(); have != "ok\n" {
+		t.Fatalf("partial line: have %q", have)
+	}
+	w.WriteString("OR\nER")
+	w.Flush()
+	want := "ok\n\033[0;31mERROR" + ASCIIReset + "\nER" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterOverlap(t *testing.T) {
+	red, blue, bold := New(Red, 0), New(Blue, 0), &Hue{Attr: Bold}
+	for _, tc := range []struct {
+		policy OverlapPolicy
+		want   string
+	}{
+		{OverlapLast, "\033[0;34mab\033[0;1mcd" + ASCIIReset},
+		{OverlapFirst, "\033[0;31mab\033[0;1mcd" + ASCIIReset},
+		{OverlapLongest, "\033[0;31mab\033[0;1mcd" + ASCIIReset},
+	} {
+		var b bytes.Buffer
+		w := NewRegexpWriter(&b)
+		w.SetOverlapPolicy(tc.policy)
+		w.AddRuleString(red, "abc")
+		w.AddRuleString(blue, "ab")
+		w.AddRulePriority(bold, regexp.MustCompile("cd"), 1)
+		w.WriteString("abcd")
+		if have := b.String(); have != tc.want {
+			t.Errorf("policy %d: have %q, want %q", tc.policy, have, tc.want)
+		}
+	}
+}
+
+func TestRegexpWriterGroups(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red, cyan := New(Red, 0), New(Cyan, 0)
+	w.AddRuleGroups(regexp.MustCompile(`" (\d{3}) .* (\d+ms)$`), map[int]*Hue{1: red, 2: cyan})
+	w.WriteString(`"GET /" 200 512 12ms`)
+	want := `"GET /" ` + "\033[0;31m200" + ASCIIReset + " 512 \033[0;36m12ms" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterPlainRule(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddRuleString(New(Red, 0), "a+")
+	w.AddRulePriority(&Hue{}, regexp.MustCompile("b"), 1)
+	w.WriteString("aba")
+	want := "\033[0;31ma" + ASCIIReset + "b\033[0;31ma" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterGroupsMissing(t *testing.T) {
+	for _, g := range []int{-1, 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("group %d: no panic", g)
+				}
+			}()
+			NewRegexpWriter(io.Discard).AddRuleGroups(regexp.MustCompile(`(a)`), map[int]*Hue{g: New(Red, 0)})
+		}()
+	}
+}
+
+func TestRegexpWriterRemoveRule(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	a := w.AddRuleString(New(Red, 0), "a")
+	w.AddRuleString(New(Blue, 0), "b")
+	if !w.RemoveRule(a) || w.RemoveRule(a) {
+		t.Fatal("RemoveRule: rule not removed exactly once")
+	}
+	w.WriteString("ab")
+	if have, want := b.String(), "a\033[0;34mb"+ASCIIReset; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestAddRuleStringErr(t *testing.T) {
+	w := NewRegexpWriter(io.Discard)
+	if _, err := w.AddRuleStringErr(New(Red, 0), "a("); err == nil {
+		t.Error("AddRuleStringErr: no error for a bad regexp")
+	}
+	if _, err := w.AddRuleStringPOSIXErr(New(Red, 0), `\d`); err == nil {
+		t.Error("AddRuleStringPOSIXErr: no error for a Perl class")
+	}
+	if id, err := w.AddRuleStringErr(New(Red, 0), "a+"); err != nil || !w.RemoveRule(id) {
+		t.Errorf("AddRuleStringErr: %v", err)
+	}
+}
+
+func TestRegexpWriterFunc(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	green, red := New(Green, 0), New(Red, 0)
+	w.AddRuleFunc(regexp.MustCompile(`\d{3}`), func(m []byte) *Hue {
+		switch m[0] {
+		case '2':
+			return green
+		case '5':
+			return red
+		}
+		return nil
+	})
+	w.WriteString("200 404 500")
+	want := "\033[0;32m200" + ASCIIReset + " 404 \033[0;31m500" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterWholeLine(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red := New(Red, 0)
+	if !w.SetWholeLine(w.AddRuleString(red, "FATAL"), true) {
+		t.Fatal("SetWholeLine: rule not found")
+	}
+	w.WriteString("ok\nx FATAL y\nok")
+	want := "ok\n\033[0;31mx FATAL y" + ASCIIReset + "\nok"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterGrep(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.SetGrep(true)
+	w.AddRuleString(New(Red, 0), "ERROR")
+	n, err := w.WriteString("a\nb ERROR\nc\nd\nERROR")
+	if n != 19 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	want := "b \033[0;31mERROR" + ASCIIReset + "\n\033[0;31mERROR" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	b.Reset()
+	w.SetEnabled(false)
+	w.WriteString("a\nb ERROR\nc\n")
+	if have := b.String(); have != "b ERROR\n" {
+		t.Errorf("disabled: have %q", have)
+	}
+}
+
+func TestRegexpWriterInvert(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddRuleString(nil, "ERROR.*")
+	w.SetInvert(&Hue{Attr: Dim})
+	w.WriteString("12:00 ERROR x")
+	want := "\033[0;2m12:00 " + ASCIIReset + "ERROR x"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterAttrRuns(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddRuleString(&Hue{Fg: Red, Attr: Bold | Underline}, "a+")
+	w.AddRuleString(&Hue{Attr: Reverse}, "b+")
+	w.AddRuleString(&Hue{UnderlineStyle: UnderlineCurly, UnderlineColor: Color256(1)}, "c+")
+	w.WriteString("aabbcc.")
+
+	// Each run starts from a reset, so no attribute leaks into the next
+	want := "\033[0;1;4;31maa\033[0;7mbb\033[0;4:3;58;5;1mcc" + ASCIIReset + "."
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterStats(t *testing.T) {
+	w := NewRegexpWriter(io.Discard)
+	a := w.AddRuleString(New(Red, 0), "a+")
+	n := w.AddRuleString(New(Blue, 0), "never")
+	w.WriteString("a aa")
+	w.WriteString("aaa")
+	want := []RuleStats{{a, 3, 6}, {n, 0, 0}}
+	if have := w.Stats(); len(have) != 2 || have[0] != want[0] || have[1] != want[1] {
+		t.Errorf("have %+v, want %+v", have, want)
+	}
+	w.ResetStats()
+	if have := w.Stats(); have[0].Matches != 0 {
+		t.Errorf("after ResetStats: have %+v", have)
+	}
+	w.SetEnabled(false)
+	w.WriteString("aa a")
+	if have := w.Stats(); have[0].Matches != 2 || have[0].Bytes != 3 {
+		t.Errorf("colors off: have %+v", have)
+	}
+
+	w = NewRegexpWriter(io.Discard)
+	x := w.AddRuleString(New(Red, 0), "x*")
+	w.WriteString("abc")
+	if have := w.Stats(); have[0] != (RuleStats{x, 0, 0}) {
+		t.Errorf("empty matches: have %+v", have)
+	}
+}
+
+// closeWriter records whether it was closed
+type closeWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *closeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestRegexpWriterClose(t *testing.T) {
+	var cw closeWriter
+	w := NewRegexpWriter(&cw)
+	w.SetLineBuffered(true)
+	w.AddRuleString(New(Red, 0), "b")
+	w.WriteString("a\nab")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := cw.String(), "a\na\033[0;31mb"+ASCIIReset+ASCIIReset; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if !cw.closed {
+		t.Error("underlying writer not closed")
+	}
+}
+
+func TestRegexpWriterRules(t *testing.T) {
+	w := NewRegexpWriter(io.Discard)
+	red := New(Red, 0).WithAttr(Bold)
+	a := w.AddRuleString(red, "ERROR.*")
+	b := w.AddRulePriority(nil, regexp.MustCompile(`\d+`), 2)
+	rules := w.Rules()
+	if len(rules) != 2 || rules[0].ID != a || rules[1].ID != b {
+		t.Fatalf("have %v", rules)
+	}
+	rules[0].Hue.SetFg(Blue)
+	if red.Fg != Red {
+		t.Errorf("Rules returned the rule's hue")
+	}
+	for i, want := range []string{`#1 "ERROR.*" bold blue`, `#2 "\\d+" - priority 2`} {
+		if have := rules[i].String(); have != want {
+			t.Errorf("rule %d: have %q, want %q", i, have, want)
+		}
+	}
+}
+
+func TestRegexpWriterDisableRule(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red, cyan := New(Red, 0), New(Cyan, 0)
+	w.AddRuleString(red, "ab")
+	high := w.AddRulePriority(cyan, regexp.MustCompile("b"), 1)
+	if !w.DisableRule(high) || w.DisableRule(99) {
+		t.Fatal("DisableRule")
+	}
+	w.WriteString("ab")
+	if have, want := b.String(), "\033[0;31mab"+ASCIIReset; have != want {
+		t.Errorf("disabled: have %q, want %q", have, want)
+	}
+	if w.Rules()[1].Enabled || w.Stats()[1].Matches != 0 {
+		t.Errorf("disabled rule is enabled or counted")
+	}
+
+	b.Reset()
+	w.EnableRule(high)
+	w.WriteString("ab")
+	if have, want := b.String(), "\033[0;31ma\033[0;36mb"+ASCIIReset; have != want {
+		t.Errorf("enabled: have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterMaxBuffer(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.AddRuleString(New(Red, 0), "ab")
+	w.SetLineBuffered(true)
+	w.SetMaxBuffer(4)
+	w.WriteString("xab")
+	if b.Len() != 0 {
+		t.Fatalf("wrote %q before the limit", b.String())
+	}
+	w.WriteString("yy")
+	want := "x\033[0;31mab" + ASCIIReset + "yy"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	if len(w.partial) != 0 {
+		t.Errorf("held %q", w.partial)
+	}
+}
+
+func TestRegexpWriterConcurrentRules(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRegexpWriter(&buf)
+	red := New(Red, 0)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			w.WriteString("error: disk full\n")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			w.RemoveRule(w.AddRuleString(red, "error"))
+			if i%10 == 0 {
+				w.FlushRules()
+			}
+		}
+	}()
+	wg.Wait()
+	if got := strings.Count(buf.String(), ": disk full\n"); got != 100 {
+		t.Fatalf("wrote %d lines, want 100", got)
+	}
+}
+
+func TestRegexpWriterMultiline(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.SetMultiline(true)
+	red := New(Red, 0)
+	w.AddRuleString(red, `(?m)^Exception.*\n(\s+at .*\n)*`)
+	w.WriteString("ok\nException: x\n")
+	w.WriteString("  at a\n")
+	if have := b.String(); have != "ok\n" {
+		t.Fatalf("trace not held back: have %q", have)
+	}
+	w.WriteString("  at b\ndone\n")
+	want := "ok\n\033[0;31mException: x\n  at a\n  at b\n" + ASCIIReset + "done\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRegexpWriterMultilineSplit(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	w.SetMultiline(true)
+	w.AddRuleString(New(Red, 0), "BEGIN\nEND")
+	w.WriteString("x\nBEGIN\n")
+	if have := b.String(); have != "x\n" {
+		t.Fatalf("start of match not held back: have %q", have)
+	}
+	w.WriteString("END\n")
+	want := "x\n\033[0;31mBEGIN\nEND" + ASCIIReset + "\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,52 @@
+package hue
+
+import (
+	"sort"
+	"sync"
+)
+
+// The style registry maps semantic names, such as "error" or "timestamp",
+// to hues. It is safe for concurrent use.
+var registry = struct {
+	sync.RWMutex
+	m map[string]Hue
+}{m: make(map[string]Hue)}
+
+// Register binds the name to a copy of the hue h, replacing any hue
+// previously registered under that name.
+func Register(name string, h *Hue) {
+	registry.Lock()
+	registry.m[name] = *h
+	registry.Unlock()
+}
+
+// Unregister removes the hue registered under the name
+func Unregister(name string) {
+	registry.Lock()
+	delete(registry.m, name)
+	registry.Unlock()
+}
+
+// Style returns a copy of the hue registered under the name, or nil
+// if there is none.
+func Style(name string) *Hue {
+	registry.RLock()
+	h, ok := registry.m[name]
+	registry.RUnlock()
+	if !ok {
+		return nil
+	}
+	return &h
+}
+
+// StyleNames returns the names of all registered hues in sorted order
+func StyleNames() []string {
+	registry.RLock()
+	names := make([]string, 0, len(registry.m))
+	for k := range registry.m {
+		names = append(names, k)
+	}
+	registry.RUnlock()
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,32 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	h := New(Red, Default)
+	Register("test.error", h)
+	Register("test.warn", New(Brown, Default))
+	defer Unregister("test.error")
+	defer Unregister("test.warn")
+
+	h.SetFg(Blue)
+	if s := Style("test.error"); s == nil || *s != *New(Red, Default) {
+		t.Errorf("Style: have %+v", s)
+	}
+	if s := Style("test.missing"); s != nil {
+		t.Errorf("Style: expected nil for a missing name, have %+v", s)
+	}
+
+	var names []string
+	for _, v := range StyleNames() {
+		if v == "test.error" || v == "test.warn" {
+			names = append(names, v)
+		}
+	}
+	if want := []string{"test.error", "test.warn"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("StyleNames: have %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,84 @@
+package hue
+
+import "regexp"
+
+// AddRuleReplace binds a hue to a regular expression whose matches are
+// replaced with repl before the text is colored, like sed with colors.
+// Inside repl, $1 and ${name} are expanded as by regexp.Regexp.Expand.
+// For example, to shorten UUIDs and highlight what is left of them:
+//
+//	w.AddRuleReplace(cyan, regexp.MustCompile(`\b([0-9a-f]{8})-[0-9a-f-]{27}\b`), "$1…")
+//
+// Replacements are made in the order the rules were added, even while
+// colorization is off, and the other rules match the replaced text. If
+// a later replacement overlaps the text of an earlier one, the earlier
+// one is left uncolored. The rule has priority 0.
+func (w *RegexpWriter) AddRuleReplace(h *Hue, re *regexp.Regexp, repl string) RuleID {
+	return w.add(rule{Hue: h, Regexp: re, replace: true, repl: repl})
+}
+
+// replacement is the text a replacing rule wrote
+type replacement struct {
+	rule int
+	span
+}
+
+// hasReplace reports whether any rule replaces its matches
+func (w *RegexpWriter) hasReplace() bool {
+	for _, r := range w.rules {
+		if r.replace && !r.off {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite makes the replacements of the replacing rules in p, in order,
+// and returns the result. The replaced text is recorded in w.replaced.
+func (w *RegexpWriter) rewrite(p []byte) []byte {
+	w.replaced = w.replaced[:0]
+	for i, r := range w.rules {
+		if !r.replace || r.off {
+			continue
+		}
+		ms := r.FindAllSubmatchIndex(p, -1)
+		if len(ms) == 0 {
+			continue
+		}
+		var t []byte
+		var spans []span
+		last := 0
+		for _, m := range ms {
+			t = append(t, p[last:m[0]]...)
+			start := len(t)
+			t = r.Expand(t, []byte(r.repl), p, m)
+			spans = append(spans, span{start, len(t)})
+			last = m[1]
+		}
+		t = append(t, p[last:]...)
+
+		// Move the earlier replacements to where they are in t
+		kept := w.replaced[:0]
+	Earlier:
+		for _, rp := range w.replaced {
+			delta := 0
+			for k, m := range ms {
+				if m[0] < rp.end && rp.start < m[1] {
+					continue Earlier
+				}
+				if m[1] <= rp.start {
+					delta += spans[k].end - spans[k].start - (m[1] - m[0])
+				}
+			}
+			rp.start += delta
+			rp.end += delta
+			kept = append(kept, rp)
+		}
+		w.replaced = kept
+		for _, sp := range spans {
+			w.replaced = append(w.replaced, replacement{i, sp})
+		}
+		p = t
+	}
+	return p
+}
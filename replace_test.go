@@ -0,0 +1,30 @@
+package hue
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestRegexpWriterReplace(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	red, cyan := New(Red, 0), New(Cyan, 0)
+	w.AddRuleReplace(red, regexp.MustCompile(`password=\S+`), "password=***")
+	w.AddRuleReplace(cyan, regexp.MustCompile(`id=(\w{4})\w+`), "id=$1")
+	n, err := w.WriteString("id=abcdefgh password=hunter2 ok")
+	if n != 31 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	want := "\033[0;36mid=abcd" + ASCIIReset + " \033[0;31mpassword=***" + ASCIIReset + " ok"
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	b.Reset()
+	w.SetEnabled(false)
+	w.WriteString("password=x")
+	if have := b.String(); have != "password=***" {
+		t.Errorf("disabled: have %q", have)
+	}
+}
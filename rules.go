@@ -0,0 +1,244 @@
+package hue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ruleSpec is a rule as read by LoadRules
+type ruleSpec struct {
+	Pattern string   `json:"pattern"`
+	Fg      string   `json:"fg"`
+	Bg      string   `json:"bg"`
+	Attrs   []string `json:"attrs"`
+}
+
+// LoadRules reads a list of rules and adds them to the RegexpWriter in
+// order, as AddRule would. Each rule has a pattern, and optionally fg
+// and bg colors, named as in Parse, and a list of attributes. If any
+// rule is invalid, LoadRules returns an error and adds none of them.
+//
+// The list is either JSON:
+//
+//	[
+//		{"pattern": "ERROR.*", "fg": "red", "attrs": ["bold"]},
+//		{"pattern": "\\d+ms", "fg": "cyan"}
+//	]
+//
+// or a YAML sequence of flat mappings:
+//
+//	# my rules
+//	- pattern: 'ERROR.*'
+//	  fg: red
+//	  attrs: [bold]
+//	- pattern: '\d+ms'
+//	  fg: cyan
+//
+// Only this subset of YAML is understood: plain, single-quoted and
+// double-quoted scalars, and flow sequences of attributes.
+func (w *RegexpWriter) LoadRules(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var specs []ruleSpec
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '[' {
+		dec := json.NewDecoder(bytes.NewReader(t))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&specs); err != nil {
+			return fmt.Errorf("hue: bad rules: %v", err)
+		}
+	} else if specs, err = parseYAMLRules(b); err != nil {
+		return err
+	}
+
+	type compiled struct {
+		h  *Hue
+		re *regexp.Regexp
+	}
+	rules := make([]compiled, 0, len(specs))
+	for i, s := range specs {
+		h, re, err := s.compile()
+		if err != nil {
+			return fmt.Errorf("hue: bad rule %d: %v", i+1, err)
+		}
+		rules = append(rules, compiled{h, re})
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, r := range rules {
+		w.rules = append(w.rules, w.newRule(rule{Hue: r.h, Regexp: r.re}))
+	}
+	return nil
+}
+
+// AddRuleSpec adds a rule written on one line as a spec accepted by
+// Parse, a colon or an at sign, and a regexp:
+//
+//	w.AddRuleSpec("bold red:ERROR|FATAL")
+//	w.AddRuleSpec(`green@^OK\b`)
+//
+// The spec ends at the first colon or at sign; the rest of s is the
+// pattern. It suits rules given as command-line flags.
+func (w *RegexpWriter) AddRuleSpec(s string) (RuleID, error) {
+	i := strings.IndexAny(s, ":@")
+	if i < 0 {
+		return 0, fmt.Errorf("hue: bad rule %q: expected spec:pattern", s)
+	}
+	h, err := Parse(s[:i])
+	if err != nil {
+		return 0, err
+	}
+	re, err := regexp.Compile(s[i+1:])
+	if err != nil {
+		return 0, fmt.Errorf("hue: bad rule %q: %v", s, err)
+	}
+	return w.AddRule(h, re), nil
+}
+
+// compile returns the hue and regexp of the rule
+func (s ruleSpec) compile() (*Hue, *regexp.Regexp, error) {
+	if s.Pattern == "" {
+		return nil, nil, fmt.Errorf("missing pattern")
+	}
+	re, err := regexp.Compile(s.Pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := new(Hue)
+	for _, c := range []struct {
+		name string
+		dst  *int
+	}{{s.Fg, &h.Fg}, {s.Bg, &h.Bg}} {
+		if c.name == "" {
+			continue
+		}
+		v, ok := colorByName(strings.ToLower(c.name))
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown color %q", c.name)
+		}
+		*c.dst = v
+	}
+	for _, name := range s.Attrs {
+		a, ok := attrByName(strings.ToLower(name))
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown attribute %q", name)
+		}
+		h.Attr |= a
+	}
+	return h, re, nil
+}
+
+// parseYAMLRules parses the YAML subset described by LoadRules
+func parseYAMLRules(b []byte) ([]ruleSpec, error) {
+	var specs []ruleSpec
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		if s == "-" || strings.HasPrefix(s, "- ") {
+			specs = append(specs, ruleSpec{})
+			if s = strings.TrimSpace(s[1:]); s == "" {
+				continue
+			}
+		} else if len(specs) == 0 {
+			return nil, fmt.Errorf("hue: bad rules line %d: expected \"- \" to start a rule", line)
+		}
+
+		colon := strings.Index(s, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("hue: bad rules line %d: expected key: value", line)
+		}
+		key, val := s[:colon], strings.TrimSpace(s[colon+1:])
+		spec := &specs[len(specs)-1]
+		var err error
+		switch key {
+		case "pattern":
+			spec.Pattern, err = yamlScalar(val)
+		case "fg":
+			spec.Fg, err = yamlScalar(val)
+		case "bg":
+			spec.Bg, err = yamlScalar(val)
+		case "attrs":
+			spec.Attrs, err = yamlList(val)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hue: bad rules line %d: %v", line, err)
+		}
+	}
+	return specs, sc.Err()
+}
+
+// yamlScalar returns the value of a plain or quoted YAML scalar,
+// followed by an optional comment
+func yamlScalar(s string) (string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), nil
+	}
+	var v, rest string
+	if s[0] == '"' {
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", err
+		}
+		if v, err = strconv.Unquote(q); err != nil {
+			return "", err
+		}
+		rest = s[len(q):]
+	} else {
+		// In single quotes, '' is a quote
+		i := 1
+		for ; i < len(s); i++ {
+			if s[i] != '\'' {
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			break
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		v, rest = strings.ReplaceAll(s[1:i], "''", "'"), s[i+1:]
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+	return v, nil
+}
+
+// yamlList returns the items of a flow sequence such as [bold, italic],
+// or of a plain scalar separated by spaces or commas
+func yamlList(s string) ([]string, error) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated list")
+		}
+		s = s[1:end]
+	} else {
+		var err error
+		if s, err = yamlScalar(s); err != nil {
+			return nil, err
+		}
+	}
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}), nil
+}
@@ -0,0 +1,70 @@
+package hue
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	want := "\033[0;1;31mERROR" + ASCIIReset + " in \033[0;36m12ms" + ASCIIReset
+	for _, rules := range []string{
+		`[{"pattern": "ERROR", "fg": "red", "attrs": ["bold"]}, {"pattern": "\\d+ms", "fg": "Cyan"}]`,
+		`
+# rules
+- pattern: 'ERROR'  # the level
+  fg: red
+  attrs: [bold]
+- pattern: "\\d+ms"
+  fg: cyan
+`,
+	} {
+		var b bytes.Buffer
+		w := NewRegexpWriter(&b)
+		if err := w.LoadRules(strings.NewReader(rules)); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteString("ERROR in 12ms")
+		if have := b.String(); have != want {
+			t.Errorf("have %q, want %q", have, want)
+		}
+	}
+
+	for _, rules := range []string{
+		`[{"pattern": "a("}]`,
+		`[{"pattern": "a", "fg": "notacolor"}]`,
+		`[{"pattern": "a", "size": 1}]`,
+		"- pattern: a\n  attrs: [loud]",
+		"fg: red",
+		"- fg: red",
+	} {
+		w := NewRegexpWriter(&bytes.Buffer{})
+		if err := w.LoadRules(strings.NewReader(rules)); err == nil {
+			t.Errorf("%q: no error", rules)
+		}
+		if len(w.rules) != 0 {
+			t.Errorf("%q: added %d rules", rules, len(w.rules))
+		}
+	}
+}
+
+func TestAddRuleSpec(t *testing.T) {
+	var b bytes.Buffer
+	w := NewRegexpWriter(&b)
+	for _, spec := range []string{"bold red:ERROR|FATAL", `green@^OK\b`} {
+		if _, err := w.AddRuleSpec(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.WriteString("OK FATAL")
+	want := "\033[0;32mOK" + ASCIIReset + " \033[0;1;31mFATAL" + ASCIIReset
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	for _, spec := range []string{"red", "notacolor:x", "red:a("} {
+		if _, err := w.AddRuleSpec(spec); err == nil {
+			t.Errorf("%q: no error", spec)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package hue
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Scanner reads colored text line by line, like a bufio.Scanner,
+// splitting each line into styled segments. Colors selected on one
+// line carry on into the next, as on a terminal, so a line inside a
+// block opened earlier has the block's style:
+//
+//	s := hue.NewScanner(out)
+//	for s.Scan() {
+//		for _, seg := range s.Segments() {
+//			...
+//		}
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+//
+// Escape sequences other than SGR are dropped.
+type Scanner struct {
+	sc    *bufio.Scanner
+	style Hue // the style selected at the end of the last line
+	start Hue // the style at the start of the current line
+	segs  []Segment
+}
+
+// NewScanner returns a Scanner reading lines from r. The lines may be
+// as long as a bufio.Scanner allows by default.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{sc: bufio.NewScanner(r)}
+}
+
+// Scan advances to the next line, which is then available through
+// Segments, Text and Style. It returns false at the end of the input
+// or on an error, which Err returns.
+func (s *Scanner) Scan() bool {
+	if !s.sc.Scan() {
+		s.segs = nil
+		return false
+	}
+	s.start = s.style
+	s.segs = tokenize(s.sc.Bytes(), &s.style)
+	return true
+}
+
+// Segments returns the styled segments of the current line, without
+// the newline. The slice is not reused by Scan.
+func (s *Scanner) Segments() []Segment {
+	return s.segs
+}
+
+// Text returns the text of the current line without color codes or
+// the newline
+func (s *Scanner) Text() string {
+	var b strings.Builder
+	for _, seg := range s.segs {
+		b.WriteString(seg.Text)
+	}
+	return b.String()
+}
+
+// Style returns the style in effect at the start of the current line,
+// as selected by the lines before it
+func (s *Scanner) Style() Hue {
+	return s.start
+}
+
+// Err returns the first error other than io.EOF that the Scanner met
+func (s *Scanner) Err() error {
+	return s.sc.Err()
+}
+
+// WholeEscapes returns a split function for a bufio.Scanner that
+// splits as split does, but never in the middle of an escape sequence:
+// a token that would end inside one is extended to its end. Tokens
+// can then be written out again, one at a time, without sending a
+// terminal half a sequence. For example, to read colored text a
+// character at a time:
+//
+//	sc := bufio.NewScanner(r)
+//	sc.Split(hue.WholeEscapes(bufio.ScanRunes))
+//
+// The token is then a rune or a whole escape sequence. A sequence
+// left unfinished at the end of the input is returned as it is.
+func WholeEscapes(split bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = split(data, atEOF)
+		if len(token) == 0 {
+			return advance, token, err
+		}
+		start := cap(data) - cap(token)
+		if start < 0 || start+len(token) > len(data) || &data[start] != &token[0] {
+			return advance, token, err // not a subslice of data
+		}
+		end, ok := seqEnd(data, start+len(token))
+		if !ok {
+			if atEOF {
+				return len(data), data[start:], err
+			}
+			return 0, nil, nil // read the rest of the sequence
+		}
+		return max(advance, end), data[start:end], err
+	}
+}
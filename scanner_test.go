@@ -0,0 +1,77 @@
+package hue
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanner(t *testing.T) {
+	in := "plain\n\033[31mred\nstill red \033[1mbold\033[0m\r\n\n\033[32m\ngreen"
+	red := Hue{Fg: Red}
+	want := []struct {
+		text  string
+		style Hue
+		segs  []Segment
+	}{
+		{"plain", Hue{}, []Segment{{"plain", Hue{}}}},
+		{"red", Hue{}, []Segment{{"red", red}}},
+		{"still red bold", red, []Segment{{"still red ", red}, {"bold", Hue{Fg: Red, Attr: Bold}}}},
+		{"", Hue{}, nil},
+		{"", Hue{}, nil},
+		{"green", Hue{Fg: Green}, []Segment{{"green", Hue{Fg: Green}}}},
+	}
+	s := NewScanner(strings.NewReader(in))
+	for i := 0; s.Scan(); i++ {
+		if i >= len(want) {
+			t.Fatalf("extra line %q", s.Text())
+		}
+		w := want[i]
+		if s.Text() != w.text || s.Style() != w.style || !reflect.DeepEqual(s.Segments(), w.segs) {
+			t.Errorf("line %d: have %q, %v, %v; want %q, %v, %v",
+				i, s.Text(), s.Style(), s.Segments(), w.text, w.style, w.segs)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWholeEscapes(t *testing.T) {
+	in := "a\033[1;31mbé\033]8;;http://x y\033\\\033[0m\033["
+	want := []string{"a", "\033[1;31m", "b", "é", "\033]8;;http://x y\033\\", "\033[0m", "\033["}
+	// A small buffer makes the scanner read sequences in parts
+	sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(in)))
+	sc.Buffer(make([]byte, 4), 64)
+	sc.Split(WholeEscapes(bufio.ScanRunes))
+	var have []string
+	for sc.Scan() {
+		have = append(have, sc.Text())
+	}
+	if sc.Err() != nil || !reflect.DeepEqual(have, want) {
+		t.Errorf("have %q, %v\nwant %q", have, sc.Err(), want)
+	}
+
+	sc = bufio.NewScanner(strings.NewReader("\033[1mone two\033[0m three"))
+	sc.Split(WholeEscapes(bufio.ScanWords))
+	have = have[:0]
+	for sc.Scan() {
+		have = append(have, sc.Text())
+	}
+	if want := []string{"\033[1mone", "two\033[0m", "three"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("words: have %q, want %q", have, want)
+	}
+
+	// ScanRunes returns a token of its own for invalid UTF-8
+	sc = bufio.NewScanner(strings.NewReader("\xff\033[1m"))
+	sc.Split(WholeEscapes(bufio.ScanRunes))
+	have = have[:0]
+	for sc.Scan() {
+		have = append(have, sc.Text())
+	}
+	if want := []string{"\ufffd", "\033[1m"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("invalid UTF-8: have %q, want %q", have, want)
+	}
+}
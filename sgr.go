@@ -0,0 +1,123 @@
+package hue
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sgrParams returns the parameters of seq if it is an SGR (select
+// graphic rendition) sequence, such as the ones Hue emits
+func sgrParams(seq []byte) (string, bool) {
+	if len(seq) < 3 || seq[0] != '\033' || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return "", false
+	}
+	params := string(seq[2 : len(seq)-1])
+	for _, c := range params {
+		if (c < '0' || c > '9') && c != ';' && c != ':' {
+			return "", false
+		}
+	}
+	return params, true
+}
+
+// apply updates h with the SGR parameters in params, as a terminal
+// would. Colors reset to the default are stored as unset.
+func (h *Hue) apply(params string) {
+	f := strings.Split(params, ";")
+	for i := 0; i < len(f); i++ {
+		if strings.Contains(f[i], ":") {
+			h.applySub(strings.Split(f[i], ":"))
+			continue
+		}
+		n, _ := strconv.Atoi(f[i])
+		switch {
+		case n == 0:
+			*h = Hue{}
+		case n == 4:
+			h.Attr |= Underline
+			h.UnderlineStyle = 0
+		case n < 10:
+			for j, c := range attrCodes {
+				if c == n {
+					h.Attr |= 1 << j
+				}
+			}
+		case n == 21:
+			h.UnderlineStyle = UnderlineDouble
+		case n == 24:
+			h.Attr &^= Underline
+			h.UnderlineStyle = 0
+		case n >= 22 && n <= 29:
+			for j, c := range attrOffCodes {
+				if c == n {
+					h.Attr &^= 1 << j
+				}
+			}
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			h.Fg = n
+		case n == 39:
+			h.Fg = 0
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			h.Bg = n - 10
+		case n == 49:
+			h.Bg = 0
+		case n == 59:
+			h.UnderlineColor = 0
+		case n == 38 || n == 48 || n == 58:
+			c, used := extColor(f[i+1:])
+			i += used
+			h.setExt(n, c)
+		}
+	}
+}
+
+// applySub applies an SGR parameter with colon-separated subparameters,
+// such as 4:3 or 38:2::255:0:0
+func (h *Hue) applySub(sub []string) {
+	switch sub[0] {
+	case "4":
+		n, _ := strconv.Atoi(sub[1])
+		if n == 0 {
+			h.Attr &^= Underline
+		}
+		h.UnderlineStyle = UnderlineStyle(min(n, int(UnderlineDashed)))
+	case "38", "48", "58":
+		n, _ := strconv.Atoi(sub[0])
+		rest := sub[1:]
+		if len(rest) == 5 && rest[0] == "2" {
+			rest = append(rest[:1], rest[2:]...) // drop the color space ID
+		}
+		c, _ := extColor(rest)
+		h.setExt(n, c)
+	}
+}
+
+// extColor parses the parameters following 38, 48 or 58: 5;n or
+// 2;r;g;b. It returns the color, 0 if they are malformed, and the
+// number of parameters used.
+func extColor(p []string) (c, used int) {
+	num := func(i int) uint8 {
+		n, _ := strconv.Atoi(p[i])
+		return uint8(n)
+	}
+	switch {
+	case len(p) >= 2 && p[0] == "5":
+		return Color256(num(1)), 2
+	case len(p) >= 4 && p[0] == "2":
+		return RGB(num(1), num(2), num(3)), 4
+	}
+	return 0, len(p)
+}
+
+// setExt sets the color selected by the SGR parameter n, which is 38,
+// 48 or 58
+func (h *Hue) setExt(n, c int) {
+	switch n {
+	case 38:
+		h.Fg = c
+	case 48:
+		h.Bg = c
+	case 58:
+		h.UnderlineColor = c
+	}
+}
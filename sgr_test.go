@@ -0,0 +1,35 @@
+package hue
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	for _, h := range []Hue{
+		{Fg: Red},
+		{Fg: BrightBlue, Bg: Green, Attr: Bold | Italic},
+		{Fg: Color256(200), Bg: RGB(1, 2, 3)},
+		{Attr: Dim, UnderlineStyle: UnderlineCurly, UnderlineColor: RGB(255, 0, 0)},
+	} {
+		var have Hue
+		have.apply(h.codes(DepthTrue))
+		if have != h {
+			t.Errorf("%q: have %+v, want %+v", h.codes(DepthTrue), have, h)
+		}
+	}
+
+	for _, tc := range []struct {
+		params string
+		want   Hue
+	}{
+		{"1;31;0", Hue{}},
+		{"1;2;22", Hue{}},
+		{"31;39;41", Hue{Bg: Red}},
+		{"38:2::1:2:3;4;24", Hue{Fg: RGB(1, 2, 3)}},
+		{"48;5;9;1", Hue{Bg: Color256(9), Attr: Bold}},
+	} {
+		var have Hue
+		have.apply(tc.params)
+		if have != tc.want {
+			t.Errorf("%q: have %+v, want %+v", tc.params, have, tc.want)
+		}
+	}
+}
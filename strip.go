@@ -0,0 +1,94 @@
+package hue
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Strip returns s without its ECMA-48 escape sequences: colors and
+// other CSI sequences, hyperlinks and other OSC strings, whoever
+// produced them. An escape sequence left unfinished at the end of s is
+// removed as well.
+func Strip(s string) string {
+	if strings.IndexByte(s, '\033') < 0 {
+		return s
+	}
+	b, _ := strip([]byte(s))
+	return string(b)
+}
+
+// StripBytes is like Strip, but for a byte slice. It returns b itself
+// if b has no escape sequences, and a new slice otherwise.
+func StripBytes(b []byte) []byte {
+	if bytes.IndexByte(b, '\033') < 0 {
+		return b
+	}
+	t, _ := strip(b)
+	return t
+}
+
+// EqualContent reports whether a and b have the same text once their
+// escape sequences are removed, as by Strip. It is meant for tests
+// that compare colored output but don't care about the colors.
+func EqualContent(a, b string) bool {
+	return Strip(a) == Strip(b)
+}
+
+// CompareContent compares the text of a and b without their escape
+// sequences, as strings.Compare would compare the text
+func CompareContent(a, b string) int {
+	return strings.Compare(Strip(a), Strip(b))
+}
+
+// strip returns the text of p without its escape sequences, and
+// whether p ends outside of one
+func strip(p []byte) (text []byte, complete bool) {
+	var l lexer
+	text = make([]byte, 0, len(p))
+	l.feed(p, func(b []byte, esc bool) {
+		if !esc {
+			text = append(text, b...)
+		}
+	})
+	return text, l.state == lexText
+}
+
+// StripWriter removes ECMA-48 escape sequences, such as colors and
+// hyperlinks, from everything written through it and writes the
+// remaining text to the underlying writer. A sequence may be split
+// across writes.
+type StripWriter struct {
+	wrapped io.Writer
+	lex     lexer
+	out     output
+}
+
+// NewStripWriter returns a StripWriter that writes plain text to w
+func NewStripWriter(w io.Writer) *StripWriter {
+	return &StripWriter{wrapped: w}
+}
+
+// Write strips the escape sequences from p and writes the text to the
+// underlying writer. On success, n is len(p).
+func (w *StripWriter) Write(p []byte) (n int, err error) {
+	w.out.reset()
+	w.lex.feed(p, func(b []byte, esc bool) {
+		if !esc {
+			w.out.copy(p, b)
+		}
+	})
+	if len(w.out.b) == 0 {
+		return len(p), nil
+	}
+	nb, err := w.wrapped.Write(w.out.b)
+	if err != nil {
+		return w.out.count(nb), err
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *StripWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
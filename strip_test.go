@@ -0,0 +1,64 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStripWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewStripWriter(&b)
+	s := string(New(Red, 0).Sprint("red")) + " " + string(Link("http://x", "link")) + "\033[1"
+	n, err := w.WriteString(s)
+	if n != len(s) || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	w.WriteString("mbold")
+	if have := b.String(); have != "red linkbold" {
+		t.Errorf("have %q", have)
+	}
+
+	sw := &shortWriter{n: 2}
+	n, err = NewStripWriter(sw).WriteString("\033[31mhello\033[0m")
+	if n != 7 || err == nil {
+		t.Errorf("short write: %d, %v", n, err)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"plain", "plain"},
+		{"\033[1;31mred\033[0m \033[38;2;1;2;3mrgb\033[m", "red rgb"},
+		{"\033]8;;http://x\alink\033]8;;\a", "link"},
+		{"\033]0;title\033\\text", "text"},
+		{"a\033(Bb\033[2Kc", "abc"},
+		{"cut\033[3", "cut"},
+	} {
+		if have := Strip(tc.in); have != tc.want {
+			t.Errorf("Strip(%q): have %q, want %q", tc.in, have, tc.want)
+		}
+		if have := StripBytes([]byte(tc.in)); string(have) != tc.want {
+			t.Errorf("StripBytes(%q): have %q, want %q", tc.in, have, tc.want)
+		}
+	}
+}
+
+func TestCompareContent(t *testing.T) {
+	red := string(New(Red, 0).Sprint("ok"))
+	for _, tc := range []struct {
+		a, b string
+		cmp  int
+	}{
+		{red, "ok", 0},
+		{red, "\033[1;32mok\033[m", 0},
+		{red, "ok!", -1},
+		{"\033[31mb", "a\033[0m", 1},
+	} {
+		if have := CompareContent(tc.a, tc.b); have != tc.cmp {
+			t.Errorf("CompareContent(%q, %q): have %d, want %d", tc.a, tc.b, have, tc.cmp)
+		}
+		if have := EqualContent(tc.a, tc.b); have != (tc.cmp == 0) {
+			t.Errorf("EqualContent(%q, %q): have %v", tc.a, tc.b, have)
+		}
+	}
+}
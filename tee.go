@@ -0,0 +1,69 @@
+package hue
+
+import (
+	"io"
+	"sync"
+)
+
+// TeeWriter duplicates its writes to two writers: a terminal, which
+// receives the output with its colors, and a plain writer, such as a
+// log file, which receives it with escape sequences removed. It is
+// usually wrapped by a Writer or RegexpWriter:
+//
+//	w := hue.NewWriter(hue.NewTeeWriter(os.Stdout, logfile), hue.New(hue.Green, 0))
+//
+// The terminal writer follows the same rules as Writer: it receives
+// plain text too while colorization is off, or if it is a file that is
+// not a terminal. A TeeWriter is safe for concurrent use.
+type TeeWriter struct {
+	mu    sync.Mutex
+	term  io.Writer
+	strip *StripWriter // term without colors
+	plain *StripWriter
+	color toggle
+}
+
+// NewTeeWriter returns a TeeWriter writing colored output to term and
+// plain output to plain
+func NewTeeWriter(term, plain io.Writer) *TeeWriter {
+	return &TeeWriter{
+		term:  term,
+		strip: NewStripWriter(term),
+		plain: NewStripWriter(plain),
+		color: detect(term),
+	}
+}
+
+// Write writes p to both writers. Like io.MultiWriter, it stops at the
+// first error.
+func (w *TeeWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	term := w.term
+	if !w.color.on() {
+		term = w.strip
+	}
+	for _, dst := range []io.Writer{term, w.plain} {
+		n, err = dst.Write(p)
+		if err != nil {
+			return n, err
+		}
+		if n != len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return len(p), nil
+}
+
+// WriteString is like Write, but writes the contents of s
+func (w *TeeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// SetEnabled overrides the program-wide Enable/Disable setting for the
+// terminal writer
+func (w *TeeWriter) SetEnabled(on bool) {
+	w.mu.Lock()
+	w.color.set(on)
+	w.mu.Unlock()
+}
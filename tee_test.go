@@ -0,0 +1,27 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTeeWriter(t *testing.T) {
+	var term, log bytes.Buffer
+	h := New(Green, 0)
+	w := NewWriter(NewTeeWriter(&term, &log), h)
+	w.WriteString("ok")
+	if have, want := term.String(), string(Encode(h, "ok")); have != want {
+		t.Errorf("term: have %q, want %q", have, want)
+	}
+	if have := log.String(); have != "ok" {
+		t.Errorf("log: have %q", have)
+	}
+
+	term.Reset()
+	tw := NewTeeWriter(&term, &log)
+	tw.SetEnabled(false)
+	tw.Write([]byte(Encode(h, "x")))
+	if have := term.String(); have != "x" {
+		t.Errorf("disabled term: have %q", have)
+	}
+}
@@ -0,0 +1,43 @@
+package hue
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// FuncMap returns functions for colorizing text/template output:
+//
+//	{{red .Name}}                   a color from StringToHue
+//	{{bold .Name}}                  an attribute, such as bold or underline
+//	{{style "error" .Msg}}          a hue from the style registry
+//	{{hue "bold red/white" .Msg}}   a spec accepted by Parse
+//
+// Each function formats its arguments like fmt.Sprint. The style
+// function writes plain text for names that are not registered. The
+// map can be converted to an html/template.FuncMap, although escape
+// codes are rarely wanted in HTML.
+func FuncMap() template.FuncMap {
+	m := template.FuncMap{
+		"style": func(name string, a ...interface{}) String {
+			h := Style(name)
+			if h == nil {
+				return String(fmt.Sprint(a...))
+			}
+			return h.Sprint(a...)
+		},
+		"hue": func(spec string, a ...interface{}) (String, error) {
+			h, err := Parse(spec)
+			if err != nil {
+				return "", err
+			}
+			return h.Sprint(a...), nil
+		},
+	}
+	for name, c := range StringToHue {
+		m[name] = New(c, 0).Sprint
+	}
+	for i, name := range attrNames {
+		m[name] = (&Hue{Attr: 1 << i}).Sprint
+	}
+	return m
+}
@@ -0,0 +1,34 @@
+package hue
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	Register("test.tmpl", New(Red, White))
+	defer Unregister("test.tmpl")
+
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{green .}} {{bold .}} {{style "test.tmpl" . 1}} {{style "test.none" .}} {{hue "cyan" .}}`))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, "x"); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		string(New(Green, 0).Sprint("x")),
+		string((&Hue{Attr: Bold}).Sprint("x")),
+		string(New(Red, White).Sprint("x", 1)),
+		"x",
+		string(New(Cyan, 0).Sprint("x")),
+	}, " ")
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	tmpl = template.Must(template.New("").Funcs(FuncMap()).Parse(`{{hue "notacolor" .}}`))
+	if err := tmpl.Execute(&b, "x"); err == nil {
+		t.Errorf("expected an error for a bad spec")
+	}
+}
@@ -0,0 +1,121 @@
+package hue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadTheme reads a theme mapping style names to specs accepted by
+// Parse, and registers the resulting hues with Register. If any entry
+// is invalid, LoadTheme returns an error and registers nothing.
+//
+// A theme is either a JSON object:
+//
+//	{"error": "bold red", "timestamp": "brightblack"}
+//
+// or a flat TOML document of string keys. Names in a TOML table are
+// prefixed with the table name and a dot:
+//
+//	# my theme
+//	error = "bold red"
+//
+//	[log]
+//	timestamp = "brightblack"	# registered as "log.timestamp"
+func LoadTheme(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var specs map[string]string
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '{' {
+		if err := json.Unmarshal(t, &specs); err != nil {
+			return fmt.Errorf("hue: bad theme: %v", err)
+		}
+	} else if specs, err = parseTOML(b); err != nil {
+		return err
+	}
+
+	hues := make(map[string]*Hue, len(specs))
+	for name, spec := range specs {
+		h, err := Parse(spec)
+		if err != nil {
+			return fmt.Errorf("hue: bad theme entry %q: %v", name, err)
+		}
+		hues[name] = h
+	}
+	for name, h := range hues {
+		Register(name, h)
+	}
+	return nil
+}
+
+// parseTOML parses the TOML subset described by LoadTheme
+func parseTOML(b []byte) (map[string]string, error) {
+	specs := make(map[string]string)
+	table := ""
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		if s[0] == '[' {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("hue: bad theme line %d: unterminated table name", line)
+			}
+			table = strings.TrimSpace(s[1:end]) + "."
+			continue
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("hue: bad theme line %d: expected name = \"spec\"", line)
+		}
+		key, err := tomlString(strings.TrimSpace(s[:eq]), true)
+		if err != nil {
+			return nil, fmt.Errorf("hue: bad theme line %d: %v", line, err)
+		}
+		val, err := tomlString(strings.TrimSpace(s[eq+1:]), false)
+		if err != nil {
+			return nil, fmt.Errorf("hue: bad theme line %d: %v", line, err)
+		}
+		specs[table+key] = val
+	}
+	return specs, sc.Err()
+}
+
+// tomlString unquotes a TOML string followed by an optional comment.
+// A bare string is accepted if bare is true.
+func tomlString(s string, bare bool) (string, error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		if bare && s != "" {
+			return s, nil
+		}
+		return "", fmt.Errorf("expected a quoted string")
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if s[0] == '"' {
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", err
+		}
+		end = len(q) - 2
+	}
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	if rest := strings.TrimSpace(s[end+2:]); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+	if s[0] == '\'' {
+		return s[1 : end+1], nil
+	}
+	return strconv.Unquote(s[:end+2])
+}
@@ -0,0 +1,53 @@
+package hue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	defer func() {
+		for _, name := range []string{"theme.error", "theme.match", "log.timestamp", "log.level"} {
+			Unregister(name)
+		}
+	}()
+
+	err := LoadTheme(strings.NewReader(`{"theme.error": "red/white", "theme.match": "#ff8800"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = LoadTheme(strings.NewReader(`
+# comment
+[log]
+timestamp = "brightblack"   # trailing comment
+"level" = 'green on default'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]Hue{
+		"theme.error":   {Fg: Red, Bg: White},
+		"theme.match":   {Fg: RGB(0xff, 0x88, 0)},
+		"log.timestamp": {Fg: BrightBlack},
+		"log.level":     {Fg: Green, Bg: Default},
+	} {
+		if h := Style(name); h == nil || *h != want {
+			t.Errorf("Style(%q): have %+v, want %+v", name, h, want)
+		}
+	}
+
+	for _, theme := range []string{
+		`{"theme.bad": 1}`,
+		`theme.bad = "notacolor"`,
+		`theme.bad = red`,
+		`theme.bad "red"`,
+		`[log`,
+	} {
+		if err := LoadTheme(strings.NewReader(theme)); err == nil {
+			t.Errorf("LoadTheme(%q): expected an error", theme)
+		}
+	}
+	if Style("theme.bad") != nil {
+		t.Errorf("an invalid theme registered a style")
+	}
+}
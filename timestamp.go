@@ -0,0 +1,34 @@
+package hue
+
+import (
+	"io"
+	"time"
+)
+
+// now returns the current time; tests replace it
+var now = time.Now
+
+// TimestampWriter starts every line written through it with the time
+// it started, in its own hue, turning a subprocess's output into a
+// timestamped log:
+//
+//	w := hue.NewTimestampWriter(os.Stdout, time.TimeOnly, hue.New(hue.Blue, 0))
+//	cmd.Stdout = w
+//
+// The time is taken when the first byte of a line is written, so a line
+// written over several calls gets one timestamp. A TimestampWriter is
+// safe for concurrent use.
+type TimestampWriter struct {
+	prefixer
+}
+
+// NewTimestampWriter returns a TimestampWriter that writes to w. Times
+// are formatted with layout, as by time.Time.Format, and followed by a
+// space.
+func NewTimestampWriter(w io.Writer, layout string, h *Hue) *TimestampWriter {
+	n := new(TimestampWriter)
+	n.init(w, func(d Depth) string {
+		return string(h.encode(d, now().Format(layout))) + " "
+	})
+	return n
+}
@@ -0,0 +1,26 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimestampWriter(t *testing.T) {
+	defer func() { now = time.Now }()
+	tick := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time {
+		tick = tick.Add(time.Second)
+		return tick
+	}
+
+	var b bytes.Buffer
+	h := New(Blue, 0)
+	w := NewTimestampWriter(&b, time.TimeOnly, h)
+	w.WriteString("a")
+	w.WriteString("b\nc\n")
+	want := string(Encode(h, "03:04:06")) + " ab\n" + string(Encode(h, "03:04:07")) + " c\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+}
@@ -0,0 +1,39 @@
+package hue
+
+// Tokenize splits p into segments of text in the style the SGR
+// sequences before them select, as a terminal would display it. Text
+// in the same style is kept in one segment, and no segment is empty.
+// Escape sequences other than SGR, such as hyperlinks, are dropped, as
+// is an unfinished sequence at the end of p. Colors reset to the
+// default are unset in the styles.
+//
+// The segments can be turned back into escape sequences for another
+// color depth with MultiWriter.WriteSegments, or rendered some other
+// way.
+func Tokenize(p []byte) []Segment {
+	var style Hue
+	return tokenize(p, &style)
+}
+
+// tokenize is Tokenize starting in the style *style. It leaves *style
+// in the style selected at the end of p.
+func tokenize(p []byte, style *Hue) []Segment {
+	var (
+		segs []Segment
+		l    lexer
+	)
+	l.feed(p, func(b []byte, esc bool) {
+		if esc {
+			if params, ok := sgrParams(b); ok {
+				style.apply(params)
+			}
+			return
+		}
+		if n := len(segs); n > 0 && segs[n-1].Style == *style {
+			segs[n-1].Text += string(b)
+			return
+		}
+		segs = append(segs, Segment{string(b), *style})
+	})
+	return segs
+}
@@ -0,0 +1,24 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	in := "plain \033[1;31mbold red\033[22m red \033]8;;http://x\033\\link\033]8;;\033\\\033[0m" +
+		"\033[38;5;208m256\033[39m end\033[4"
+	want := []Segment{
+		{"plain ", Hue{}},
+		{"bold red", Hue{Fg: Red, Attr: Bold}},
+		{" red link", Hue{Fg: Red}},
+		{"256", Hue{Fg: Color256(208)}},
+		{" end", Hue{}},
+	}
+	if have := Tokenize([]byte(in)); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v\nwant %v", have, want)
+	}
+	if have := Tokenize(nil); have != nil {
+		t.Errorf("empty: have %v", have)
+	}
+}
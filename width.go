@@ -0,0 +1,305 @@
+package hue
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Width returns the number of terminal columns s takes up when
+// displayed. Escape sequences take up none, and neither do combining
+// marks, format characters such as the zero-width joiner, and control
+// characters. East Asian wide and fullwidth characters, and emoji,
+// take up two columns; everything else takes one. Use it instead of
+// len to align colored text.
+func Width(s string) int {
+	n := 0
+	var l lexer
+	l.feed([]byte(s), func(b []byte, esc bool) {
+		if !esc {
+			n += textWidth(b)
+		}
+	})
+	return n
+}
+
+// PadLeft returns s with spaces added before it to make it width
+// columns wide, aligning it to the right. The width is measured by
+// Width; s is returned unchanged if it is wide enough already.
+func PadLeft(s String, width int) String {
+	return String(strings.Repeat(" ", fill(s, width))) + s
+}
+
+// PadRight returns s with spaces added after it to make it width
+// columns wide, aligning it to the left
+func PadRight(s String, width int) String {
+	return s + String(strings.Repeat(" ", fill(s, width)))
+}
+
+// Center returns s with spaces added on both sides to make it width
+// columns wide. If the spaces can't be split evenly, the extra one
+// goes after s.
+func Center(s String, width int) String {
+	n := fill(s, width)
+	return String(strings.Repeat(" ", n/2)) + s + String(strings.Repeat(" ", n-n/2))
+}
+
+// Truncate returns s cut to at most maxWidth columns, ending in tail,
+// such as "…", if anything was cut. The cut is made between runes,
+// never in an escape sequence, and the escape sequences before it are
+// kept; a reset, and the end of an open hyperlink, are added after the
+// tail so the text that follows is not colored. The tail is left out
+// if it is wider than maxWidth. s is returned unchanged if it fits.
+func Truncate(s String, maxWidth int, tail string) String {
+	if Width(string(s)) <= maxWidth {
+		return s
+	}
+	tw := Width(tail)
+	if tw > maxWidth {
+		tail, tw = "", 0
+	}
+	room := maxWidth - tw
+	var (
+		b       strings.Builder
+		l       lexer
+		n       int
+		done    bool
+		colored bool
+		linked  bool
+	)
+	l.feed([]byte(s), func(t []byte, esc bool) {
+		if done {
+			return
+		}
+		if esc {
+			if _, ok := sgrParams(t); ok {
+				colored = true
+			} else if bytes.HasPrefix(t, []byte("\033]8;")) {
+				linked = string(t) != OSC8End
+			}
+			b.Write(t)
+			return
+		}
+		for len(t) > 0 {
+			r, size := utf8.DecodeRune(t)
+			if n += runeWidth(r); n > room {
+				done = true
+				return
+			}
+			b.Write(t[:size])
+			t = t[size:]
+		}
+	})
+	b.WriteString(tail)
+	if linked {
+		b.WriteString(OSC8End)
+	}
+	if colored {
+		b.WriteString(ASCIIReset)
+	}
+	return String(b.String())
+}
+
+// WordWrap returns s with line breaks added so that no line is wider
+// than width columns. Lines are broken at spaces, which are dropped
+// at the breaks, and words wider than a line are split between runes.
+// The colors in effect at a break are reset before the newline and
+// selected again after it, so each line stands on its own. s is
+// returned unchanged if width is not positive.
+func WordWrap(s String, width int) String {
+	if width <= 0 {
+		return s
+	}
+	var (
+		b      strings.Builder
+		l      lexer
+		style  Hue
+		col    int
+		spaces int      // the spaces after the last word written
+		word   []string // the runes and escape sequences of the next word
+		wordW  int
+	)
+	newline := func() {
+		colored := style != Hue{}
+		if colored {
+			b.WriteString(ASCIIReset)
+		}
+		b.WriteByte('\n')
+		if colored {
+			b.WriteString(style.sgr(DepthTrue))
+		}
+		col = 0
+	}
+	emit := func(t string) {
+		if params, ok := sgrParams([]byte(t)); ok {
+			style.apply(params)
+			b.WriteString(t)
+			return
+		}
+		if t[0] == '\033' {
+			b.WriteString(t)
+			return
+		}
+		w := textWidth([]byte(t))
+		if col > 0 && col+w > width {
+			newline()
+		}
+		b.WriteString(t)
+		col += w
+	}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if wordW > 0 {
+			if col > 0 && col+spaces+wordW > width {
+				newline()
+			} else {
+				b.WriteString(strings.Repeat(" ", spaces))
+				col += spaces
+			}
+			spaces = 0
+		}
+		for _, t := range word {
+			emit(t)
+		}
+		word, wordW = word[:0], 0
+	}
+	l.feed([]byte(s), func(t []byte, esc bool) {
+		if esc {
+			word = append(word, string(t))
+			return
+		}
+		for len(t) > 0 {
+			r, size := utf8.DecodeRune(t)
+			switch r {
+			case ' ':
+				flush()
+				spaces++
+			case '\n':
+				flush()
+				b.WriteString(strings.Repeat(" ", spaces))
+				b.WriteByte('\n')
+				col, spaces = 0, 0
+			default:
+				word = append(word, string(t[:size]))
+				wordW += runeWidth(r)
+			}
+			t = t[size:]
+		}
+	})
+	flush()
+	b.WriteString(strings.Repeat(" ", spaces))
+	return String(b.String())
+}
+
+// fill returns the number of columns s is short of width
+func fill(s String, width int) int {
+	return max(0, width-Width(string(s)))
+}
+
+// textWidth returns the width of b, which holds no escape sequences
+func textWidth(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += runeWidth(r)
+		b = b[size:]
+	}
+	return n
+}
+
+// runeWidth returns the number of columns r takes up
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x20 && r < 0x7f:
+		return 1
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, zeroWidth):
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	}
+	return 1
+}
+
+// zeroWidth holds the characters that combine with the ones before
+// them but aren't in the Mn, Me or Cf categories: the medial vowels
+// and final consonants of Hangul Jamo, and the emoji skin tone
+// modifiers
+var zeroWidth = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1160, 0x11ff, 1},
+		{0xd7b0, 0xd7ff, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f3fb, 0x1f3ff, 1},
+	},
+}
+
+// wide holds the East Asian wide and fullwidth characters and the
+// emoji displayed in two columns
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1},
+		{0x231a, 0x231b, 1},
+		{0x2329, 0x232a, 1},
+		{0x23e9, 0x23ec, 1},
+		{0x23f0, 0x23f3, 3},
+		{0x25fd, 0x25fe, 1},
+		{0x2614, 0x2615, 1},
+		{0x2648, 0x2653, 1},
+		{0x267f, 0x2693, 20},
+		{0x26a1, 0x26aa, 9},
+		{0x26ab, 0x26bd, 18},
+		{0x26be, 0x26c4, 6},
+		{0x26c5, 0x26ce, 9},
+		{0x26d4, 0x26ea, 22},
+		{0x26f2, 0x26f3, 1},
+		{0x26f5, 0x26fa, 5},
+		{0x26fd, 0x2705, 8},
+		{0x270a, 0x270b, 1},
+		{0x2728, 0x274c, 36},
+		{0x274e, 0x2753, 5},
+		{0x2754, 0x2755, 1},
+		{0x2757, 0x2795, 62},
+		{0x2796, 0x2797, 1},
+		{0x27b0, 0x27bf, 15},
+		{0x2b1b, 0x2b1c, 1},
+		{0x2b50, 0x2b55, 5},
+		{0x2e80, 0x303e, 1},
+		{0x3041, 0x33ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xa000, 0xa4cf, 1},
+		{0xa960, 0xa97f, 1},
+		{0xac00, 0xd7a3, 1},
+		{0xf900, 0xfaff, 1},
+		{0xfe10, 0xfe19, 1},
+		{0xfe30, 0xfe6f, 1},
+		{0xff00, 0xff60, 1},
+		{0xffe0, 0xffe6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x16fe0, 0x16fe4, 1},
+		{0x17000, 0x18aff, 1},
+		{0x1b000, 0x1b2ff, 1},
+		{0x1f004, 0x1f0cf, 203},
+		{0x1f18e, 0x1f191, 3},
+		{0x1f192, 0x1f19a, 1},
+		{0x1f200, 0x1f202, 1},
+		{0x1f210, 0x1f23b, 1},
+		{0x1f240, 0x1f248, 1},
+		{0x1f250, 0x1f251, 1},
+		{0x1f260, 0x1f265, 1},
+		{0x1f300, 0x1f64f, 1},
+		{0x1f680, 0x1f6ff, 1},
+		{0x1f7e0, 0x1f7eb, 1},
+		{0x1f90c, 0x1f9ff, 1},
+		{0x1fa70, 0x1faff, 1},
+		{0x20000, 0x2fffd, 1},
+		{0x30000, 0x3fffd, 1},
+	},
+}
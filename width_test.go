@@ -0,0 +1,91 @@
+package hue
+
+import "testing"
+
+func TestWidth(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{string(Encode(New(Red, 0), "hello")), 5},
+		{"\033]8;;http://x\033\\link\033]8;;\033\\", 4},
+		{"日本語", 6},
+		{"ｈｉ", 4},
+		{"e\u0301", 1},
+		{"👍\U0001f3fd", 2},
+		{"한글", 4},
+		{"a\tb\n", 2},
+		{"été", 3},
+	} {
+		if have := Width(tc.s); have != tc.want {
+			t.Errorf("Width(%q): have %d, want %d", tc.s, have, tc.want)
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	red := Encode(New(Red, 0), "日本")
+	for _, tc := range []struct {
+		have, want String
+	}{
+		{PadLeft(red, 6), "  " + red},
+		{PadRight(red, 6), red + "  "},
+		{Center(red, 7), " " + red + "  "},
+		{PadRight(red, 3), red},
+		{Center("ab", 2), "ab"},
+	} {
+		if tc.have != tc.want {
+			t.Errorf("have %q, want %q", tc.have, tc.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	red := "\033[31m"
+	for _, tc := range []struct {
+		s    String
+		w    int
+		tail string
+		want String
+	}{
+		{"hello", 5, "…", "hello"},
+		{"hello world", 8, "…", "hello w…"},
+		{String(red + "hello" + ASCIIReset + " world"), 4, "…", String(red + "hel…" + ASCIIReset)},
+		{"日本語", 5, "…", "日本…"},
+		{"日本語", 4, "", "日本"},
+		{"abc", 2, "...", "ab"},
+		{Link("http://x", "a link"), 3, ".", String(linkStart("http://x") + "a ." + OSC8End)},
+	} {
+		have := Truncate(tc.s, tc.w, tc.tail)
+		if have != tc.want {
+			t.Errorf("Truncate(%q, %d): have %q, want %q", tc.s, tc.w, have, tc.want)
+		}
+		if w := Width(string(have)); w > tc.w {
+			t.Errorf("Truncate(%q, %d): %d columns", tc.s, tc.w, w)
+		}
+	}
+}
+
+func TestWordWrap(t *testing.T) {
+	red := "\033[31m"
+	for _, tc := range []struct {
+		s    String
+		w    int
+		want String
+	}{
+		{"the quick brown fox", 10, "the quick\nbrown fox"},
+		{"a\nb c", 3, "a\nb c"},
+		{"abcdefgh", 3, "abc\ndef\ngh"},
+		{"日本語", 4, "日本\n語"},
+		{String(red + "one two" + ASCIIReset + " three"), 5,
+			String(red + "one" + ASCIIReset + "\n\033[31mtwo" + ASCIIReset + "\nthree")},
+		{"x", 0, "x"},
+	} {
+		have := WordWrap(tc.s, tc.w)
+		if have != tc.want {
+			t.Errorf("WordWrap(%q, %d): have %q, want %q", tc.s, tc.w, have, tc.want)
+		}
+	}
+}
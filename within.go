@@ -0,0 +1,95 @@
+package hue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// attrOffCodes holds the SGR parameter that turns off each attribute
+// in bit order. Bold and Dim share 22.
+var attrOffCodes = [...]int{22, 23, 24, 29, 22, 25, 27, 28}
+
+// EncodeWithin is like Encode, except the result is meant to be embedded
+// in text already styled with outer. Instead of a full reset, it emits
+// only the parameters that differ from outer and afterwards restores
+// outer's style, so the enclosing text keeps its colors:
+//
+//	line := &hue.Hue{Bg: hue.Blue}
+//	line.Println("status:", hue.EncodeWithin(line, &hue.Hue{Fg: hue.Red}, "fail"), "(3 tests)")
+//
+// Unset colors in h are inherited from outer, as with Merge.
+func EncodeWithin(outer, h *Hue, a ...interface{}) String {
+	d := inherit.depth()
+	s := fmt.Sprint(a...)
+	inner := outer.Merge(h)
+	open, close := transition(outer, inner, d), transition(inner, outer, d)
+	if open == "" {
+		return String(s)
+	}
+	return String("\033[" + open + "m" + s + "\033[" + close + "m")
+}
+
+// transition returns the ECMA-48 parameters that change the style from
+// to to at the depth d, or "" if nothing changes.
+func transition(from, to *Hue, d Depth) string {
+	if d == DepthNone {
+		return ""
+	}
+	var p []string
+	code := func(c int) { p = append(p, strconv.Itoa(c)) }
+
+	// Turning attributes off. SGR 22 and 24 turn off more than one
+	// attribute, so the survivors are turned on again below.
+	off := from.Attr &^ to.Attr
+	if from.UnderlineStyle != 0 && to.UnderlineStyle == 0 {
+		off |= Underline
+	}
+	again := Attr(0)
+	for i, c := range attrOffCodes {
+		a := Attr(1 << i)
+		if off&a == 0 {
+			continue
+		}
+		code(c)
+		switch a {
+		case Bold:
+			again |= to.Attr & Dim
+		case Dim:
+			again |= to.Attr & Bold
+		case Underline:
+			again |= to.Attr & Underline
+		}
+	}
+	on := to.Attr&^from.Attr | again
+	for i, c := range attrCodes {
+		a := Attr(1 << i)
+		if on&a != 0 && !(a == Underline && to.UnderlineStyle != 0) {
+			code(c)
+		}
+	}
+	if to.UnderlineStyle != 0 && (to.UnderlineStyle != from.UnderlineStyle || again&Underline != 0) {
+		p = append(p, "4:"+strconv.Itoa(int(to.UnderlineStyle)))
+	}
+
+	color := func(from, to int, bg bool, reset int) {
+		f, t := Degrade(normalize(from), d), Degrade(normalize(to), d)
+		switch {
+		case f == t:
+		case ValidColor(t):
+			p = append(p, colorCode(t, bg))
+		default:
+			code(reset)
+		}
+	}
+	color(from.Fg, to.Fg, false, Default)
+	color(from.Bg, to.Bg, true, Default+10)
+
+	if f, t := underlineCode(normalize(from.UnderlineColor), d), underlineCode(normalize(to.UnderlineColor), d); f != t {
+		if t == "" {
+			t = "59"
+		}
+		p = append(p, t)
+	}
+	return strings.Join(p, ";")
+}
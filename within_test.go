@@ -0,0 +1,21 @@
+package hue
+
+import "testing"
+
+func TestEncodeWithin(t *testing.T) {
+	for _, tc := range []struct {
+		outer, h *Hue
+		want     String
+	}{
+		{&Hue{Bg: Blue}, &Hue{Fg: Red}, "\033[31mx\033[39m"},
+		{&Hue{Fg: Green, Bg: Blue}, &Hue{Fg: Red, Attr: Bold}, "\033[1;31mx\033[22;32m"},
+		{&Hue{Attr: Bold}, &Hue{Attr: Dim}, "\033[2mx\033[22;1m"},
+		{&Hue{Fg: Red}, &Hue{Fg: Red}, "x"},
+		{&Hue{Attr: Underline}, &Hue{UnderlineStyle: UnderlineCurly, UnderlineColor: Red}, "\033[4:3;58;5;1mx\033[24;4;59m"},
+		{&Hue{}, &Hue{Fg: Red, Bg: White}, "\033[31;47mx\033[39;49m"},
+	} {
+		if have := EncodeWithin(tc.outer, tc.h, "x"); have != tc.want {
+			t.Errorf("EncodeWithin(%+v, %+v): have %q, want %q", *tc.outer, *tc.h, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hue
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWriterConcurrent(t *testing.T) {
+	var b bytes.Buffer
+	h := New(Red, Default)
+	w := NewWriter(&b, h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w.WriteString("abc")
+			}
+		}()
+	}
+	wg.Wait()
+
+	unit := string(Encode(h, "abc"))
+	if have := b.String(); have != strings.Repeat(unit, 800) {
+		t.Errorf("writes interleaved")
+	}
+}
+
+// shortWriter accepts at most n bytes
+type shortWriter struct {
+	bytes.Buffer
+	n int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		w.Buffer.Write(p[:w.n])
+		return w.n, io.ErrShortWrite
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriterCount(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b, New(Red, Default))
+	n, err := w.Write([]byte("hello"))
+	if n != 5 || err != nil {
+		t.Errorf("Write: have %d, %v", n, err)
+	}
+	if w.Written() != int64(b.Len()) {
+		t.Errorf("Written: have %d, want %d", w.Written(), b.Len())
+	}
+
+	sw := &shortWriter{n: len("\033[31;49m") + 2}
+	n, err = NewWriter(sw, New(Red, Default)).Write([]byte("hello"))
+	if n != 2 || err != io.ErrShortWrite {
+		t.Errorf("short Write: have %d, %v", n, err)
+	}
+}
+
+func TestWriterCountEscapes(t *testing.T) {
+	for _, tc := range []struct {
+		mode EscapeMode
+		out  string // what the underlying writer takes
+	}{
+		{EscapeStrip, "\033[31;49mab"},
+		{EscapePass, "\033[31;49ma\033[0m\033[1;31;49mb"},
+	} {
+		w := NewWriter(&shortWriter{n: len(tc.out)}, New(Red, Default))
+		w.SetEscapeMode(tc.mode)
+		n, err := w.Write([]byte("a\033[1mbc"))
+		if n != len("a\033[1mb") || err != io.ErrShortWrite {
+			t.Errorf("mode %d: have %d, %v", tc.mode, n, err)
+		}
+	}
+}
+
+func TestWriterClose(t *testing.T) {
+	var b bytes.Buffer
+	bw := bufio.NewWriter(&b)
+	var c io.WriteCloser = NewWriter(bw, New(Red, 0))
+	c.Write([]byte("x"))
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := b.String(), string(Encode(New(Red, 0), "x"))+ASCIIReset; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	b.Reset()
+	c = NewRegexpWriter(&b)
+	c.Close()
+	if have := b.String(); have != ASCIIReset {
+		t.Errorf("RegexpWriter: have %q", have)
+	}
+}
+
+func TestWriterLineRestart(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b, New(Red, 0))
+	w.SetLineRestart(true)
+	n, err := w.Write([]byte("a\n\nb\n"))
+	if n != 5 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	red := New(Red, 0)
+	want := fmt.Sprint(Encode(red, "a"), "\n\n", Encode(red, "b"), "\n")
+	if have := b.String(); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestWriterEscapeMode(t *testing.T) {
+	in := "a" + string(New(Green, 0).Sprint("b")) + "c"
+	for _, tc := range []struct {
+		mode EscapeMode
+		want string
+	}{
+		{EscapeWrap, "\033[31ma\033[32mb\033[0mc\033[0m"},
+		{EscapePass, "\033[31ma\033[0m\033[32mb\033[0m\033[31mc\033[0m"},
+		{EscapeStrip, "\033[31mabc\033[0m"},
+	} {
+		var b bytes.Buffer
+		w := NewWriter(&b, New(Red, 0))
+		w.SetEscapeMode(tc.mode)
+		w.WriteString(in)
+		if have := b.String(); have != tc.want {
+			t.Errorf("mode %d: have %q, want %q", tc.mode, have, tc.want)
+		}
+	}
+}
+
+func TestWriterReadFrom(t *testing.T) {
+	var b bytes.Buffer
+	h := New(Red, 0)
+	w := NewWriter(&b, h)
+	src := iotest.OneByteReader(strings.NewReader("ab"))
+	n, err := io.Copy(w, src)
+	if n != 2 || err != nil {
+		t.Fatalf("Copy: %d, %v", n, err)
+	}
+	if have, want := b.String(), string(Encode(h, "a")+Encode(h, "b")); have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,24 @@
+package hue
+
+import "io"
+
+// NewZebraWriter returns a LineWriter that colors lines with the hues
+// a and b in turn, switching every height lines, to make dense tables
+// easier to read:
+//
+//	w := hue.NewZebraWriter(os.Stdout, 1, nil, &hue.Hue{Bg: hue.Color256(236)})
+//
+// A height below 1 is taken as 1. As with any LineWriter, call Flush
+// to write a last line without a newline.
+func NewZebraWriter(w io.Writer, height int, a, b *Hue) *LineWriter {
+	height = max(height, 1)
+	line := 0
+	return NewLineWriter(w, func([]byte) *Hue {
+		h := a
+		if line/height%2 == 1 {
+			h = b
+		}
+		line++
+		return h
+	})
+}
@@ -0,0 +1,19 @@
+package hue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestZebraWriter(t *testing.T) {
+	var b bytes.Buffer
+	a, c := New(Red, 0), New(Blue, 0)
+	w := NewZebraWriter(&b, 2, a, c)
+	w.WriteString("1\n2\n3")
+	w.WriteString("\n4\n5\n")
+	want := string(Encode(a, "1")+"\n"+Encode(a, "2")+"\n"+Encode(c, "3")+"\n"+
+		Encode(c, "4")+"\n"+Encode(a, "5")) + "\n"
+	if have := b.String(); have != want {
+		t.Errorf("have %q\nwant %q", have, want)
+	}
+}